@@ -0,0 +1,338 @@
+// Package client is a typed client for the HTTP API of the malo-id-generator (see the idgenerator package for the server).
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An IdType is the name of one of the supported ID types
+type IdType string
+
+// the supported ID types
+const (
+	MaLo IdType = "MALO"
+	NeLo IdType = "NELO"
+	MeLo IdType = "MELO"
+	TR   IdType = "TRID"
+	SR   IdType = "SRID"
+)
+
+// defaultRetryBackoff is the wait time before the first retry; it's doubled for each further retry
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// Options configure a Client. The zero value is a client without retries that uses http.DefaultClient.
+type Options struct {
+	HttpClient   *http.Client
+	Retries      int           // Retries is how often a request is retried after network errors, 429 and 5xx responses (except 501)
+	RetryBackoff time.Duration // RetryBackoff is the wait time before the first retry (default: 200ms); it's doubled for each further retry
+}
+
+// A Client calls the API of a malo-id-generator deployment. It's safe for concurrent use.
+type Client struct {
+	baseUrl *url.URL
+	options Options
+}
+
+// New returns a client for the API at baseUrl, e.g. "https://markt.lokations.id" or "http://localhost:8080/tools/ids" if the routes are mounted below a prefix
+func New(baseUrl string, options Options) (*Client, error) {
+	parsedUrl, err := url.Parse(strings.TrimSuffix(baseUrl, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL '%s': %w", baseUrl, err)
+	}
+	if parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https" {
+		return nil, fmt.Errorf("the base URL has to start with http:// or https:// but was '%s'", baseUrl)
+	}
+	if options.HttpClient == nil {
+		options.HttpClient = http.DefaultClient
+	}
+	if options.RetryBackoff == 0 {
+		options.RetryBackoff = defaultRetryBackoff
+	}
+	if options.Retries < 0 {
+		return nil, fmt.Errorf("the number of retries must not be negative but was %d", options.Retries)
+	}
+	return &Client{baseUrl: parsedUrl, options: options}, nil
+}
+
+// A GeneratedId is a freshly generated ID as returned by /json
+type GeneratedId struct {
+	Id       string
+	Type     string // Type is the label of the type, e.g. "MaLo"
+	Checksum string // Checksum is empty for types without checksum (MeLo)
+	// Fields contains all fields of the response, including the type specific ones, e.g. "issuer" for MaLos or "netzbetreibernummer" for MeLos
+	Fields map[string]string
+}
+
+// UnmarshalJSON reads the flat JSON object that the API returns for each ID
+func (g *GeneratedId) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &g.Fields); err != nil {
+		return err
+	}
+	g.Id, g.Type, g.Checksum = g.Fields["id"], g.Fields["type"], g.Fields["checksum"]
+	return nil
+}
+
+// A ValidationResult is the result of the validation of a single value
+type ValidationResult struct {
+	Row               int    `json:"row"`
+	Value             string `json:"value"`
+	Type              string `json:"type,omitempty"` // Type is the requested or detected ID type; empty if it could not be detected
+	Valid             bool   `json:"valid"`
+	Reason            string `json:"reason,omitempty"`            // Reason describes why the value is invalid
+	CorrectedChecksum string `json:"correctedChecksum,omitempty"` // CorrectedChecksum is set if the value has the correct structure but the wrong checksum
+	CorrectedId       string `json:"correctedId,omitempty"`       // CorrectedId is the value with the CorrectedChecksum
+}
+
+// A ChecksumStep describes how a single character of an ID contributes to its checksum
+type ChecksumStep struct {
+	Position  int    `json:"position"` // Position is 1-based
+	Character string `json:"character"`
+	Value     int    `json:"value"` // Value is the digit itself or, for letters, its ASCII code
+	Weight    int    `json:"weight"`
+	Product   int    `json:"product"`
+}
+
+// A ChecksumExplanation describes the calculation of the check digit of an ID step by step
+type ChecksumExplanation struct {
+	Type              string         `json:"type"`
+	Id                string         `json:"id"` // Id is the complete ID with the correct checksum
+	IdWithoutChecksum string         `json:"idWithoutChecksum"`
+	Steps             []ChecksumStep `json:"steps"`
+	OddSum            int            `json:"oddSum"`
+	EvenSum           int            `json:"evenSum"`
+	WeightedEvenSum   int            `json:"weightedEvenSum"`
+	Sum               int            `json:"sum"`
+	Remainder         int            `json:"remainder"`
+	NextMultipleOf10  int            `json:"nextMultipleOf10"`
+	Checksum          string         `json:"checksum"`
+	GivenChecksum     string         `json:"givenChecksum,omitempty"` // GivenChecksum is the last character of the explained ID, if a complete ID was given
+}
+
+// the errors that an ApiError matches with errors.Is, depending on its status code
+var (
+	ErrInvalidRequest = errors.New("invalid request")                              // 400, e.g. an unknown type or an invalid ID
+	ErrNotConfigured  = errors.New("the endpoint is not configured on the server") // 501, e.g. no ID_TYPE_TO_GENERATE
+	ErrUnavailable    = errors.New("the server is unavailable")                    // network errors, 429 and 5xx (except 501), after all retries
+	ErrUnexpected     = errors.New("the server returned an unexpected response")   // other status codes and malformed responses
+)
+
+// An ApiError is returned if the API responds with an error status code
+type ApiError struct {
+	StatusCode int
+	Message    string // Message is the "error" of the JSON response or the plain response body
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("malo-id-generator API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Is maps the status code to ErrInvalidRequest, ErrNotConfigured, ErrUnavailable or ErrUnexpected
+func (e *ApiError) Is(target error) bool {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return target == ErrInvalidRequest
+	case e.StatusCode == http.StatusNotImplemented:
+		return target == ErrNotConfigured
+	case isRetryableStatus(e.StatusCode):
+		return target == ErrUnavailable
+	default:
+		return target == ErrUnexpected
+	}
+}
+
+// isRetryableStatus returns true for the status codes of (presumably) temporary errors
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || (statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
+// newApiError reads the error message from the response
+func newApiError(response *http.Response) *ApiError {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	var errorResponse struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error != "" {
+		message = errorResponse.Error
+	}
+	return &ApiError{StatusCode: response.StatusCode, Message: message}
+}
+
+// do sends the request (and retries it, if configured) and returns the response if its status is 200. The caller has to close the body.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	requestUrl := c.baseUrl.JoinPath(path)
+	requestUrl.RawQuery = query.Encode()
+	backoff := c.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, method, requestUrl.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		response, err := c.options.HttpClient.Do(request)
+		switch {
+		case err == nil && response.StatusCode == http.StatusOK:
+			return response, nil
+		case err == nil:
+			err = newApiError(response)
+			_ = response.Body.Close()
+			if !isRetryableStatus(response.StatusCode) {
+				return nil, err
+			}
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			err = fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		if attempt >= c.options.Retries {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// getJson sends a GET request and decodes the JSON response into target
+func (c *Client) getJson(ctx context.Context, path string, query url.Values, target any) error {
+	response, err := c.do(ctx, http.MethodGet, path, query, "", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if err = json.NewDecoder(response.Body).Decode(target); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnexpected, err)
+	}
+	return nil
+}
+
+// Generate returns a new ID of the given type. If the type is empty, the type configured on the server (ID_TYPE_TO_GENERATE) is generated.
+func (c *Client) Generate(ctx context.Context, idType IdType) (GeneratedId, error) {
+	if idType == "" {
+		var generatedId GeneratedId
+		err := c.getJson(ctx, "/json", url.Values{}, &generatedId)
+		return generatedId, err
+	}
+	generatedIds, err := c.GenerateBatch(ctx, idType, 1)
+	if err != nil {
+		return GeneratedId{}, err
+	}
+	return generatedIds[0], nil
+}
+
+// GenerateBatch returns count new IDs of the given type (or the type configured on the server, if idType is empty)
+func (c *Client) GenerateBatch(ctx context.Context, idType IdType, count int) ([]GeneratedId, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("the count has to be positive but was %d", count)
+	}
+	query := url.Values{"count": {strconv.Itoa(count)}}
+	if idType != "" {
+		query.Set("type", string(idType))
+	}
+	response, err := c.do(ctx, http.MethodGet, "/stream", query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	generatedIds := make([]GeneratedId, 0, count)
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		var generatedId GeneratedId
+		if err = json.Unmarshal(scanner.Bytes(), &generatedId); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnexpected, err)
+		}
+		if message, isError := generatedId.Fields["error"]; isError {
+			// the stream has already started, so the server can only report errors in the body
+			return nil, &ApiError{StatusCode: http.StatusInternalServerError, Message: message}
+		}
+		generatedIds = append(generatedIds, generatedId)
+	}
+	if err = scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnexpected, err)
+	}
+	if len(generatedIds) != count {
+		return nil, fmt.Errorf("%w: expected %d IDs but got %d", ErrUnexpected, count, len(generatedIds))
+	}
+	return generatedIds, nil
+}
+
+// Validate validates the values and returns one result per value, in the same order. If idType is empty, the type of each value is detected.
+func (c *Client) Validate(ctx context.Context, idType IdType, values ...string) ([]ValidationResult, error) {
+	if len(values) == 0 {
+		return []ValidationResult{}, nil
+	}
+	query := url.Values{"header": {"false"}, "column": {"2"}}
+	if idType != "" {
+		query.Set("type", string(idType))
+	}
+	response, err := c.do(ctx, http.MethodPost, "/validate", query, "text/csv; charset=utf-8", validationCsv(values))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	var rowResults []ValidationResult
+	if err = json.NewDecoder(response.Body).Decode(&rowResults); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnexpected, err)
+	}
+	// the server skips empty values, so their results are filled in here; all others are matched by their row
+	results := make([]ValidationResult, len(values))
+	found := make([]bool, len(values))
+	for _, result := range rowResults {
+		index := result.Row - 1
+		if index < 0 || index >= len(values) || found[index] {
+			return nil, fmt.Errorf("%w: unexpected row %d in the validation results", ErrUnexpected, result.Row)
+		}
+		result.Value = values[index]
+		results[index], found[index] = result, true
+	}
+	for index, value := range values {
+		if found[index] {
+			continue
+		}
+		if strings.TrimSpace(value) != "" {
+			return nil, fmt.Errorf("%w: no validation result for row %d", ErrUnexpected, index+1)
+		}
+		results[index] = ValidationResult{Row: index + 1, Value: value, Reason: "the value is empty"}
+	}
+	return results, nil
+}
+
+// validationCsv returns the values as quoted values of the second column of a CSV, so that separators and line breaks within the values are kept.
+// The empty first column makes sure that the server detects the input as CSV with ";" as separator, even if the first value contains a line break.
+func validationCsv(values []string) []byte {
+	var buffer bytes.Buffer
+	for _, value := range values {
+		buffer.WriteString(`;"` + strings.ReplaceAll(value, `"`, `""`) + `"` + "\n")
+	}
+	return buffer.Bytes()
+}
+
+// Explain explains the checksum calculation of the ID step by step. If idType is empty, the type is detected from the ID, which requires the complete ID (with checksum).
+func (c *Client) Explain(ctx context.Context, idType IdType, id string) (ChecksumExplanation, error) {
+	query := url.Values{"id": {id}, "format": {"json"}}
+	if idType != "" {
+		query.Set("type", string(idType))
+	}
+	var explanation ChecksumExplanation
+	err := c.getJson(ctx, "/explain", query, &explanation)
+	return explanation, err
+}
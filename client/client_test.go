@@ -0,0 +1,179 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/client"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestInit(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// newClient starts the real router and returns a client for it
+func (s *Suite) newClient(handler http.Handler, baseUrlSuffix string, options client.Options) *client.Client {
+	server := httptest.NewServer(handler)
+	s.T().Cleanup(server.Close)
+	apiClient, err := client.New(server.URL+baseUrlSuffix, options)
+	then.AssertThat(s.T(), err, is.Nil())
+	return apiClient
+}
+
+// failingHandler responds with the status code to the first failures requests and passes the others on to the handler
+func failingHandler(handler http.Handler, failures int32, statusCode int, requests *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, `{"error": "try again later"}`, statusCode)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (s *Suite) Test_Generate_The_Configured_Type() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	generatedId, err := apiClient.Generate(context.Background(), "")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(generatedId.Id), is.Nil())
+	then.AssertThat(s.T(), generatedId.Type, is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), generatedId.Checksum, is.EqualTo(generatedId.Id[10:]))
+	then.AssertThat(s.T(), generatedId.Fields["issuer"] != "", is.True())
+}
+
+func (s *Suite) Test_Generate_A_Batch() {
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	generatedIds, err := apiClient.GenerateBatch(context.Background(), client.NeLo, 5)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(generatedIds), is.EqualTo(5))
+	for _, generatedId := range generatedIds {
+		then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(generatedId.Id), is.Nil())
+	}
+	generatedId, err := apiClient.Generate(context.Background(), client.MeLo)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), generatedId.Fields["netzbetreibernummer"], is.EqualTo(generatedId.Id[2:8]))
+}
+
+func (s *Suite) Test_Validate() {
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	results, err := apiClient.Validate(context.Background(), "", "41373559241", "41373559240", "E1137355921")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(results), is.EqualTo(3))
+	then.AssertThat(s.T(), results[0].Valid, is.True())
+	then.AssertThat(s.T(), results[1].Valid, is.False())
+	then.AssertThat(s.T(), results[1].CorrectedId, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), results[2].Type, is.EqualTo("NeLo"))
+
+	results, err = apiClient.Validate(context.Background(), client.NeLo, "41373559241")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), results[0].Valid, is.False())
+}
+
+func (s *Suite) Test_Validate_Returns_One_Result_Per_Value() {
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	values := []string{"41373559241", "", "4137355924;1", "E113735,5921", "   ", "\"41373559241\"", "4137\n3559241", "41373559240"}
+	results, err := apiClient.Validate(context.Background(), "", values...)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(results), is.EqualTo(len(values)))
+	for index, result := range results {
+		then.AssertThat(s.T(), result.Row, is.EqualTo(index+1))
+		then.AssertThat(s.T(), result.Value, is.EqualTo(values[index]))
+	}
+	then.AssertThat(s.T(), results[0].Valid, is.True())
+	then.AssertThat(s.T(), results[1].Reason, is.EqualTo("the value is empty"))
+	then.AssertThat(s.T(), results[2].Valid, is.False())
+	then.AssertThat(s.T(), results[3].Valid, is.False())
+	then.AssertThat(s.T(), results[4].Valid, is.False())
+	then.AssertThat(s.T(), results[5].Valid, is.False())
+	then.AssertThat(s.T(), results[6].Valid, is.False())
+	then.AssertThat(s.T(), results[7].CorrectedId, is.EqualTo("41373559241"))
+}
+
+func (s *Suite) Test_Explain() {
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	explanation, err := apiClient.Explain(context.Background(), "", "41373559241")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), explanation.Checksum, is.EqualTo("1"))
+	then.AssertThat(s.T(), len(explanation.Steps), is.EqualTo(10))
+
+	explanation, err = apiClient.Explain(context.Background(), client.MaLo, "4137355924")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), explanation.Id, is.EqualTo("41373559241"))
+}
+
+func (s *Suite) Test_Typed_Errors() {
+	apiClient := s.newClient(idgenerator.NewRouter(), "", client.Options{})
+	_, err := apiClient.GenerateBatch(context.Background(), "FOO", 1)
+	then.AssertThat(s.T(), errors.Is(err, client.ErrInvalidRequest), is.True())
+	var apiError *client.ApiError
+	then.AssertThat(s.T(), errors.As(err, &apiError), is.True())
+	then.AssertThat(s.T(), apiError.StatusCode, is.EqualTo(http.StatusBadRequest))
+	then.AssertThat(s.T(), apiError.Message, is.StringContaining("FOO"))
+
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "")
+	_, err = apiClient.Generate(context.Background(), "")
+	then.AssertThat(s.T(), errors.Is(err, client.ErrNotConfigured), is.True())
+
+	_, err = apiClient.Explain(context.Background(), client.MeLo, "DE0010696664610000000000000012345")
+	then.AssertThat(s.T(), errors.Is(err, client.ErrInvalidRequest), is.True())
+}
+
+func (s *Suite) Test_Retries() {
+	var requests atomic.Int32
+	apiClient := s.newClient(failingHandler(idgenerator.NewRouter(), 2, http.StatusServiceUnavailable, &requests), "", client.Options{Retries: 2, RetryBackoff: time.Millisecond})
+	_, err := apiClient.Generate(context.Background(), client.TR)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), requests.Load(), is.EqualTo(int32(3)))
+
+	requests.Store(0)
+	apiClient = s.newClient(failingHandler(idgenerator.NewRouter(), 2, http.StatusServiceUnavailable, &requests), "", client.Options{Retries: 1, RetryBackoff: time.Millisecond})
+	_, err = apiClient.Generate(context.Background(), client.TR)
+	then.AssertThat(s.T(), errors.Is(err, client.ErrUnavailable), is.True())
+	then.AssertThat(s.T(), requests.Load(), is.EqualTo(int32(2)))
+
+	_, err = apiClient.GenerateBatch(context.Background(), "FOO", 1) // client errors are not retried
+	then.AssertThat(s.T(), errors.Is(err, client.ErrInvalidRequest), is.True())
+	then.AssertThat(s.T(), requests.Load(), is.EqualTo(int32(3)))
+}
+
+func (s *Suite) Test_Context_Cancellation() {
+	var requests atomic.Int32
+	apiClient := s.newClient(failingHandler(idgenerator.NewRouter(), 100, http.StatusBadGateway, &requests), "", client.Options{Retries: 5, RetryBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := apiClient.Generate(ctx, client.SR) // is cancelled while waiting for the retry
+	then.AssertThat(s.T(), errors.Is(err, context.DeadlineExceeded), is.True())
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = apiClient.Validate(cancelled, "", "41373559241")
+	then.AssertThat(s.T(), errors.Is(err, context.Canceled), is.True())
+}
+
+func (s *Suite) Test_Routes_Below_A_Prefix() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	apiClient := s.newClient(idgenerator.NewHandler("/tools/ids", idgenerator.RouteOptions{IdType: "SRID"}), "/tools/ids/", client.Options{})
+	generatedId, err := apiClient.Generate(context.Background(), "")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), idgenerator.SRIdType.Validate(generatedId.Id), is.Nil())
+}
+
+func (s *Suite) Test_Invalid_Options() {
+	_, err := client.New("markt.lokations.id", client.Options{})
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	_, err = client.New("https://markt.lokations.id", client.Options{Retries: -1})
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
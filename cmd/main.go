@@ -0,0 +1,29 @@
+package main
+
+import (
+	"embed"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// companyStylesheet contains the files of the companystylesheet submodule that are served by the idgenerator (see idgenerator.RouteOptions.CompanyAssets)
+//
+//go:embed static/companystylesheet/css/hochfrequenz.css
+//go:embed static/companystylesheet/fonts/Roboto/Roboto-Regular.ttf static/companystylesheet/fonts/Roboto/Roboto-Medium.ttf static/companystylesheet/fonts/Roboto/Roboto-Bold.ttf
+//go:embed static/companystylesheet/logo_weiss.png static/companystylesheet/symbol_weiss.png
+var companyStylesheet embed.FS
+
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(idgenerator.RunCli(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	companyAssets, err := fs.Sub(companyStylesheet, "static/companystylesheet")
+	if err != nil {
+		log.Panic(err)
+	}
+	if err = idgenerator.Serve(idgenerator.RouteOptions{CompanyAssets: companyAssets}); err != nil {
+		log.Panic(err)
+	}
+}
@@ -0,0 +1,243 @@
+package idgenerator
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// the ways in which a typo can be detected
+const (
+	detectedByPattern  = "pattern"    // the variant does not even match the structure of the ID type (e.g. a letter in a MaLo)
+	detectedByChecksum = "checksum"   // the variant has the correct structure, but its checksum is wrong
+	notDetected        = "undetected" // the variant is a valid ID itself
+)
+
+// maxAnalysisSampleSize is the maximum number of random IDs per type that may be analysed in a single HTTP request
+const maxAnalysisSampleSize = 1000
+
+// An AnalysedTypo is a single typo of a valid ID and whether it would be detected
+type AnalysedTypo struct {
+	Variant   string `json:"variant"`
+	Kind      string `json:"kind"`     // Kind is either "substitution" or "transposition"
+	Position  int    `json:"position"` // Position is the (1-based) position of the (first) character that was changed
+	Detection string `json:"detection"`
+}
+
+// TypoDetectionStatistics counts how many typos of a kind were detected in which way
+type TypoDetectionStatistics struct {
+	Total              int `json:"total"`
+	DetectedByPattern  int `json:"detectedByPattern"`
+	DetectedByChecksum int `json:"detectedByChecksum"`
+	Undetected         int `json:"undetected"`
+	// ChecksumDetectionRate is the share of the typos that match the ID pattern which are detected by the checksum.
+	// Typos that are already detected by the pattern are left out because the checksum is not needed to detect them.
+	ChecksumDetectionRate float64 `json:"checksumDetectionRate"`
+	// OverallDetectionRate is the share of all typos that are detected (either by pattern or by checksum)
+	OverallDetectionRate float64 `json:"overallDetectionRate"`
+}
+
+func (s *TypoDetectionStatistics) add(detection string) {
+	s.Total++
+	switch detection {
+	case detectedByPattern:
+		s.DetectedByPattern++
+	case detectedByChecksum:
+		s.DetectedByChecksum++
+	case notDetected:
+		s.Undetected++
+	}
+}
+
+func (s *TypoDetectionStatistics) calculateRates() {
+	if matchingPattern := s.DetectedByChecksum + s.Undetected; matchingPattern > 0 {
+		s.ChecksumDetectionRate = float64(s.DetectedByChecksum) / float64(matchingPattern)
+	}
+	if s.Total > 0 {
+		s.OverallDetectionRate = float64(s.DetectedByPattern+s.DetectedByChecksum) / float64(s.Total)
+	}
+}
+
+// ChecksumStrengthAnalysis describes which typos of a single valid ID the checksum of its type detects
+type ChecksumStrengthAnalysis struct {
+	Id             string                  `json:"id"`
+	Type           string                  `json:"type"`
+	Substitutions  TypoDetectionStatistics `json:"substitutions"`
+	Transpositions TypoDetectionStatistics `json:"transpositions"`
+	Typos          []AnalysedTypo          `json:"typos"`
+}
+
+// ChecksumStrengthSummary aggregates the ChecksumStrengthAnalysis of many random IDs of the same type
+type ChecksumStrengthSummary struct {
+	Type           string                  `json:"type"`
+	SampleSize     int                     `json:"sampleSize"`
+	Substitutions  TypoDetectionStatistics `json:"substitutions"`
+	Transpositions TypoDetectionStatistics `json:"transpositions"`
+}
+
+// classifyTypo returns how (if at all) the given variant of a valid ID is detected
+func classifyTypo(idType IdType, variant string) string {
+	if !idType.pattern.MatchString(variant) {
+		return detectedByPattern
+	}
+	if idType.Validate(variant) != nil {
+		return detectedByChecksum
+	}
+	return notDetected
+}
+
+// AnalyseChecksumStrength produces every single substitution and adjacent transposition of the given valid id and reports which of them the checksum detects
+func AnalyseChecksumStrength(idType IdType, id string) (ChecksumStrengthAnalysis, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !idType.HasChecksum() {
+		return ChecksumStrengthAnalysis{}, fmt.Errorf("%s-IDs have no checksum", idType.Label)
+	}
+	if err := idType.Validate(id); err != nil {
+		return ChecksumStrengthAnalysis{}, fmt.Errorf("only valid IDs can be analysed: %w", err)
+	}
+	analysis := ChecksumStrengthAnalysis{Id: id, Type: idType.Label, Typos: []AnalysedTypo{}}
+	for _, variant := range singleTypoVariants(id) {
+		detection := classifyTypo(idType, variant.id)
+		if variant.kind == transpositionTypo {
+			analysis.Transpositions.add(detection)
+		} else {
+			analysis.Substitutions.add(detection)
+		}
+		analysis.Typos = append(analysis.Typos, AnalysedTypo{Variant: variant.id, Kind: variant.kind, Position: variant.position, Detection: detection})
+	}
+	analysis.Substitutions.calculateRates()
+	analysis.Transpositions.calculateRates()
+	return analysis, nil
+}
+
+// SummariseChecksumStrength analyses sampleSize random IDs (from the existing generators) of the given type and aggregates the detection rates
+func SummariseChecksumStrength(idType IdType, sampleSize int) (ChecksumStrengthSummary, error) {
+	summary := ChecksumStrengthSummary{Type: idType.Label, SampleSize: sampleSize}
+	for i := 0; i < sampleSize; i++ {
+		id, err := idType.NewRandomId()
+		if err != nil {
+			return ChecksumStrengthSummary{}, err
+		}
+		analysis, err := AnalyseChecksumStrength(idType, id)
+		if err != nil {
+			return ChecksumStrengthSummary{}, err
+		}
+		for _, typo := range analysis.Typos {
+			if typo.Kind == transpositionTypo {
+				summary.Transpositions.add(typo.Detection)
+			} else {
+				summary.Substitutions.add(typo.Detection)
+			}
+		}
+	}
+	summary.Substitutions.calculateRates()
+	summary.Transpositions.calculateRates()
+	return summary, nil
+}
+
+// summariseChecksumStrengthOfTypes returns a ChecksumStrengthSummary for each of the given types. If no types are given, all types with a checksum are summarised.
+func summariseChecksumStrengthOfTypes(idTypes []IdType, sampleSize int) ([]ChecksumStrengthSummary, error) {
+	if len(idTypes) == 0 {
+		for _, idType := range supportedIdTypes {
+			if idType.HasChecksum() {
+				idTypes = append(idTypes, idType)
+			}
+		}
+	}
+	summaries := make([]ChecksumStrengthSummary, 0, len(idTypes))
+	for _, idType := range idTypes {
+		summary, err := SummariseChecksumStrength(idType, sampleSize)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// checksumStrengthHandler analyses the ID from the "id" query parameter or, if the "sample" query parameter is set instead, returns the aggregated detection rates of as many random IDs per type
+func checksumStrengthHandler(c *gin.Context) {
+	id := strings.ToUpper(strings.TrimSpace(c.Query("id")))
+	if id != "" {
+		idType, err := getRequestedIdType(c, id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		analysis, err := AnalyseChecksumStrength(idType, id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, analysis)
+		return
+	}
+	sampleSize, err := strconv.Atoi(c.DefaultQuery("sample", "100"))
+	if err != nil || sampleSize < 1 || sampleSize > maxAnalysisSampleSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the query parameter 'sample' must be a number between 1 and %d", maxAnalysisSampleSize)})
+		return
+	}
+	var idTypes []IdType
+	if typeName := c.Query("type"); typeName != "" {
+		idType, typeErr := getIdType(typeName)
+		if typeErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": typeErr.Error()})
+			return
+		}
+		idTypes = append(idTypes, idType)
+	}
+	summaries, err := summariseChecksumStrengthOfTypes(idTypes, sampleSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// analyzeCommand is the CLI equivalent of the checksumStrengthHandler: it analyses the IDs given as arguments or, if there are none, summarises -sample random IDs per type
+func analyzeCommand(args []string, _ io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("analyze", stdout)
+	typeName := flagSet.String("type", "", "the ID type (MALO, NELO, TRID or SRID); detected from the ID if not set; all types with a checksum are summarised if not set")
+	sampleSize := flagSet.Int("sample", 1000, "the number of random IDs per type that are analysed if no ID is given")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if *sampleSize < 1 || *sampleSize > maxAnalysisSampleSize {
+		return fmt.Errorf("the flag -sample must be a number between 1 and %d", maxAnalysisSampleSize)
+	}
+	var idTypes []IdType
+	if *typeName != "" {
+		idType, err := getIdType(*typeName)
+		if err != nil {
+			return err
+		}
+		idTypes = append(idTypes, idType)
+	}
+	if flagSet.NArg() == 0 {
+		summaries, err := summariseChecksumStrengthOfTypes(idTypes, *sampleSize)
+		if err != nil {
+			return err
+		}
+		return writeJson(stdout, summaries)
+	}
+	for _, id := range flagSet.Args() {
+		idType, ok := detectIdType(id)
+		if len(idTypes) == 1 {
+			idType, ok = idTypes[0], true
+		}
+		if !ok {
+			return fmt.Errorf("could not detect the type of '%s'; please provide the -type flag", id)
+		}
+		analysis, err := AnalyseChecksumStrength(idType, id)
+		if err != nil {
+			return err
+		}
+		if err = writeJson(stdout, analysis); err != nil {
+			return err
+		}
+	}
+	return nil
+}
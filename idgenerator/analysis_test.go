@@ -0,0 +1,96 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"strings"
+)
+
+func (s *Suite) Test_MaLo_Checksum_Detects_All_Transpositions_Of_Example() {
+	analysis, err := idgenerator.AnalyseChecksumStrength(idgenerator.MaLoIdType, "41373559241")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), analysis.Transpositions.Undetected, is.EqualTo(0))
+	then.AssertThat(s.T(), analysis.Transpositions.ChecksumDetectionRate, is.EqualTo(1.0))
+	// 11 positions * 35 other characters
+	then.AssertThat(s.T(), analysis.Substitutions.Total, is.EqualTo(11*35))
+	then.AssertThat(s.T(), analysis.Substitutions.Total, is.EqualTo(analysis.Substitutions.DetectedByPattern+analysis.Substitutions.DetectedByChecksum+analysis.Substitutions.Undetected))
+	for _, typo := range analysis.Typos {
+		then.AssertThat(s.T(), typo.Detection == "undetected", is.EqualTo(idgenerator.MaLoIdType.Validate(typo.Variant) == nil))
+	}
+}
+
+func (s *Suite) Test_Only_Valid_Ids_Can_Be_Analysed() {
+	_, err := idgenerator.AnalyseChecksumStrength(idgenerator.MaLoIdType, "41373559240")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Checksum_Strength_Summary() {
+	summary, err := idgenerator.SummariseChecksumStrength(idgenerator.NeLoIdType, 10)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), summary.SampleSize, is.EqualTo(10))
+	then.AssertThat(s.T(), summary.Substitutions.Total, is.EqualTo(10*11*35))
+	then.AssertThat(s.T(), summary.Substitutions.ChecksumDetectionRate, is.GreaterThan(0.5))
+	then.AssertThat(s.T(), summary.Substitutions.ChecksumDetectionRate, is.LessThanOrEqualTo(1.0))
+}
+
+func (s *Suite) Test_Analyze_Endpoint() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/analyze?id=41373559241")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var analysis idgenerator.ChecksumStrengthAnalysis
+	err := json.NewDecoder(response.Body).Decode(&analysis)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), analysis.Type, is.EqualTo("MaLo"))
+
+	response = performGetRequest(router, "/analyze?sample=5&type=SRID")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var summaries []idgenerator.ChecksumStrengthSummary
+	err = json.NewDecoder(response.Body).Decode(&summaries)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(summaries), is.EqualTo(1))
+	then.AssertThat(s.T(), summaries[0].Type, is.EqualTo("SR"))
+
+	response = performGetRequest(router, "/analyze?sample=1000000")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
+
+func (s *Suite) Test_Analyze_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"analyze", "-sample", "3"}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	var summaries []idgenerator.ChecksumStrengthSummary
+	err := json.Unmarshal(stdout.Bytes(), &summaries)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(summaries), is.EqualTo(4)) // MaLo, NeLo, TR, SR
+
+	stdout.Reset()
+	exitCode = idgenerator.RunCli([]string{"analyze", "E1137355921"}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	then.AssertThat(s.T(), strings.Contains(stdout.String(), `"type": "NeLo"`), is.True())
+
+	for _, sampleSize := range []string{"0", "-1", "1000000000"} {
+		stdout.Reset()
+		stderr.Reset()
+		exitCode = idgenerator.RunCli([]string{"analyze", "-sample", sampleSize}, strings.NewReader(""), &stdout, &stderr)
+		then.AssertThat(s.T(), exitCode, is.EqualTo(1))
+		then.AssertThat(s.T(), strings.Contains(stderr.String(), "-sample"), is.True())
+	}
+}
+
+func (s *Suite) Test_Unknown_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"foobar"}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(2))
+	then.AssertThat(s.T(), strings.Contains(stderr.String(), "analyze"), is.True())
+}
+
+func (s *Suite) Test_No_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli(nil, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(2))
+	then.AssertThat(s.T(), strings.Contains(stderr.String(), "Usage"), is.True())
+}
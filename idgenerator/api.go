@@ -0,0 +1,303 @@
+package idgenerator
+
+import (
+	"embed"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Serve starts the gRPC server (if configured) and serves the router with the given options with the configured runtime (see runRouter)
+func Serve(options RouteOptions) error {
+	if err := startGrpcServer(); err != nil {
+		return err
+	}
+	return runRouter(newRouter(options))
+}
+
+// NewRouter creates a gin engine and bind the handlers to the API paths
+func NewRouter() *gin.Engine {
+	return newRouter(RouteOptions{})
+}
+
+func newRouter(options RouteOptions) *gin.Engine {
+	router := gin.Default()
+	router.Use(httpInvocationMiddleware(router))
+	RegisterRoutes(&router.RouterGroup, options)
+	return router
+}
+
+// RouteOptions configure the routes that are registered by RegisterRoutes
+type RouteOptions struct {
+	// IdType is the name of the type (e.g. "MALO") that / and /json generate and that all endpoints with an optional type default to. If empty, ID_TYPE_TO_GENERATE is used.
+	IdType string
+	// WithoutFunctionTriggers skips the endpoints of the Azure Functions with non-HTTP triggers (e.g. /generate-queue), which are only called by the Functions host
+	WithoutFunctionTriggers bool
+	// CompanyAssets contains the files of the Hochfrequenz company stylesheet (css/hochfrequenz.css, fonts/Roboto/Roboto-{Regular,Medium,Bold}.ttf, logo_weiss.png and symbol_weiss.png).
+	// They are not embedded in this package because they live in a git submodule, which go module downloads leave out; the binary in cmd embeds and passes them.
+	// Without them, /hfstyle, /roboto-*, /logo and /symbol respond with 404 and the pages fall back to the default fonts.
+	CompanyAssets fs.FS
+}
+
+// the keys under which the routeOptionsMiddleware stores the RouteOptions in the gin.Context
+const (
+	basePathKey = "malo-id-generator/basePath"
+	idTypeKey   = "malo-id-generator/idType"
+)
+
+// routeOptionsMiddleware makes the base path of the routes and the RouteOptions available to the handlers
+func routeOptionsMiddleware(basePath string, options RouteOptions) gin.HandlerFunc {
+	basePath = strings.TrimSuffix(basePath, "/")
+	return func(c *gin.Context) {
+		c.Set(basePathKey, basePath)
+		c.Set(idTypeKey, options.IdType)
+		c.Next()
+	}
+}
+
+// RegisterRoutes binds the handlers to the API paths below the group, e.g. to mount the generator under /tools/ids of another gin engine.
+// The links between the HTML pages and the assets (stylesheets, fonts, images) respect the base path of the group.
+func RegisterRoutes(group *gin.RouterGroup, options RouteOptions) {
+	// a sub group, so that the middleware doesn't apply to other routes of the group
+	routes := group.Group("", routeOptionsMiddleware(group.BasePath(), options))
+	// the following pathes have to match the name of the respective azure function or its route (if set, e.g. in case of function generate-malo-id whose route in function.json is "/")
+	// see this SO answer: https://stackoverflow.com/a/76419027/10009545
+	routes.GET("/", generateRandomIdHtml)
+	routes.GET("/json", generateRandomIdJson)
+	routes.GET("/style", stylesheetHandler)
+	routes.GET("/hfstyle", companyAssetHandler(options.CompanyAssets, "css/hochfrequenz.css", "text/css"))
+	routes.GET("/roboto-regular", companyAssetHandler(options.CompanyAssets, "fonts/Roboto/Roboto-Regular.ttf", "font/ttf"))
+	routes.GET("/roboto-medium", companyAssetHandler(options.CompanyAssets, "fonts/Roboto/Roboto-Medium.ttf", "font/ttf"))
+	routes.GET("/roboto-bold", companyAssetHandler(options.CompanyAssets, "fonts/Roboto/Roboto-Bold.ttf", "font/ttf"))
+	routes.GET("/logo", companyAssetHandler(options.CompanyAssets, "logo_weiss.png", "image/png"))
+	routes.GET("/symbol", companyAssetHandler(options.CompanyAssets, "symbol_weiss.png", "image/png"))
+	routes.GET("/favicon", faviconHandler)
+	routes.GET("/suggest", suggestionsHandler)
+	routes.GET("/explain", checksumExplanationHandler)
+	routes.GET("/analyze", checksumStrengthHandler)
+	routes.GET("/validate", validationFormHandler)
+	routes.POST("/validate", bulkValidationHandler)
+	routes.POST("/extract", extractionHandler)
+	routes.POST("/pseudonymize", pseudonymizationHandler)
+	routes.POST("/depseudonymize", depseudonymizationHandler)
+	routes.POST("/rewrite", rewriteHandler)
+	routes.GET("/reservations", listReservationsHandler)
+	routes.POST("/reservations", reservationHandler)
+	routes.POST("/reservations/release", releaseHandler)
+	routes.POST("/lease", leaseHandler)
+	routes.GET("/lease/:lease", getLeaseHandler)
+	routes.POST("/lease/:lease/renew", renewLeaseHandler)
+	routes.POST("/lease/:lease/release", releaseLeaseHandler)
+	routes.GET("/exclusions", exclusionsHandler)
+	routes.GET("/sequence", sequenceHandler)
+	routes.GET("/namespaces", listNamespacesHandler)
+	routes.POST("/namespaces/:namespace/ids", consumeNamespaceIdsHandler)
+	routes.GET("/namespaces/:namespace/ids/:sequence", namespaceIdHandler)
+	routes.POST("/namespaces/:namespace/release", releaseNamespaceHandler)
+	routes.GET("/jobs", listJobsHandler)
+	routes.POST("/jobs", submitJobHandler)
+	routes.GET("/jobs/:job", jobHandler)
+	routes.GET("/jobs/:job/result", jobResultHandler)
+	routes.GET("/stream", streamHandler)
+	routes.GET("/events", eventsHandler)
+	routes.GET("/ws", websocketHandler)
+	routes.GET("/graphql", graphqlHandler)
+	routes.POST("/graphql", graphqlHandler)
+	routes.POST("/rpc", jsonRpcHandler)
+	routes.POST("/chat/slack", slackHandler)
+	routes.POST("/chat/teams", teamsHandler)
+	if !options.WithoutFunctionTriggers {
+		for functionName := range invocationFunctions {
+			routes.POST("/"+functionName, invocationHandler(functionName))
+		}
+	}
+}
+
+// NewHandler returns an http.Handler that serves all routes below the prefix, e.g. for http.Handle("/tools/ids/", NewHandler("/tools/ids", RouteOptions{})).
+// The handler expects the prefix in the request paths, so it must not be wrapped in http.StripPrefix.
+func NewHandler(prefix string, options RouteOptions) http.Handler {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	RegisterRoutes(engine.Group(prefix), options)
+	return engine
+}
+
+// getConfiguredIdType checks the environment variables and decides which IdType to generate.
+// this is useful if you want to use the same code base and deploy it to different environments (with different env variables) for different ID types
+func getConfiguredIdType() (IdType, error) {
+	// set this value in local.settings.json or in the azure portal function settings
+	if idTypeToGenerate, ok := os.LookupEnv("ID_TYPE_TO_GENERATE"); ok {
+		idTypeToGenerate = strings.ToUpper(idTypeToGenerate)
+		for _, idType := range supportedIdTypes {
+			if idType.Name == idTypeToGenerate {
+				return idType, nil
+			}
+		}
+		return IdType{}, fmt.Errorf("unsupported value of environment variable 'ID_TYPE_TO_GENERATE': '%s'. Supported values are 'MALO', 'NELO', 'MELO', 'TRID' and 'SRID'", idTypeToGenerate)
+	}
+	return IdType{}, fmt.Errorf("no value set for environment variable 'ID_TYPE_TO_GENERATE'. Supported values are 'MALO', 'NELO', 'MELO', 'TRID' and 'SRID'")
+}
+
+// getIdTypeOrConfigured returns the IdType with the given name or, if the name is empty, the configured one (see getConfiguredIdType)
+func getIdTypeOrConfigured(name string) (IdType, error) {
+	if name != "" {
+		return getIdType(name)
+	}
+	return getConfiguredIdType()
+}
+
+// getConfiguredIdTypeFor returns the IdType of the RouteOptions of the request's routes or, if none is set, the configured one (see getConfiguredIdType)
+func getConfiguredIdTypeFor(c *gin.Context) (IdType, error) {
+	if name := c.GetString(idTypeKey); name != "" {
+		return getIdType(name)
+	}
+	return getConfiguredIdType()
+}
+
+// getIdTypeOrConfiguredFor returns the IdType with the given name or, if the name is empty, the one configured for the request (see getConfiguredIdTypeFor)
+func getIdTypeOrConfiguredFor(c *gin.Context, name string) (IdType, error) {
+	if name != "" {
+		return getIdType(name)
+	}
+	return getConfiguredIdTypeFor(c)
+}
+
+// getIdGenerator returns the IdGenerator for the IdType configured in the environment variables (see getConfiguredIdType)
+func getIdGenerator() (IdGenerator, error) {
+	idType, err := getConfiguredIdType()
+	if err != nil {
+		return nil, err
+	}
+	return idType.Generator(), nil
+}
+
+func generateRandomIdHtml(c *gin.Context) {
+	idType, err := getConfiguredIdTypeFor(c)
+	if err != nil {
+		c.JSON(501, gin.H{"error": err.Error()})
+		return
+	}
+	idType.Generator().GenerateId(c)
+}
+
+func generateRandomIdJson(c *gin.Context) {
+	if namespace := c.Query("namespace"); namespace != "" {
+		generateNamespacedIdJson(c, namespace)
+		return
+	}
+	idType, err := getConfiguredIdTypeFor(c)
+	if err != nil {
+		c.JSON(501, gin.H{"error": err.Error()})
+		return
+	}
+	idType.Generator().GenerateIdRaw(c)
+}
+
+func getPort() string {
+	port := ":8080"
+	if val, ok := os.LookupEnv("FUNCTIONS_CUSTOMHANDLER_PORT"); ok {
+		port = ":" + val
+	}
+	return port
+}
+
+// embedded files:
+//
+//go:embed static/style.css
+var stylesheet embed.FS
+
+// favicon is the favicon (the little icon in the browser tab)
+//
+//go:embed static/favicon.png
+var favicon embed.FS
+
+// templatesFS is the embedded file system where the template files for gin are located
+//
+//go:embed static/templates
+var templatesFS embed.FS
+
+// htmlTemplates are the parsed templates. They are rendered by renderHtml instead of the engine, so that they're also available if the routes are registered on another engine.
+var htmlTemplates = parseHTMLFromEmbedFS(templatesFS, "static/templates/*")
+
+// renderHtml renders the template with the given name and passes the base path of the routes (see RegisterRoutes) to it
+func renderHtml(c *gin.Context, name string, data gin.H) {
+	data["basePath"] = c.GetString(basePathKey)
+	c.Render(http.StatusOK, render.HTML{Template: htmlTemplates, Name: name, Data: data})
+}
+
+// returns the stylesheet as text/css
+func stylesheetHandler(c *gin.Context) {
+	stylesheetBody, err := stylesheet.ReadFile("static/style.css")
+	if err != nil {
+		response := map[string]string{}
+		c.JSON(http.StatusNotFound, response)
+	}
+	c.Data(http.StatusOK, "text/css", stylesheetBody)
+}
+
+// companyAssetHandler returns the file with the given name of the company assets (see RouteOptions.CompanyAssets)
+func companyAssetHandler(assets fs.FS, name string, contentType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if assets == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the company assets are not configured"})
+			return
+		}
+		body, err := fs.ReadFile(assets, name)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, contentType, body)
+	}
+}
+
+// returns the favicon as image/png
+func faviconHandler(c *gin.Context) {
+	stylesheetBody, err := favicon.ReadFile("static/favicon.png")
+	if err != nil {
+		response := map[string]string{}
+		c.JSON(http.StatusNotFound, response)
+	}
+	c.Data(http.StatusOK, "image/png", stylesheetBody)
+}
+
+// boilerplate code to use embedded files as HTML templates:
+// copied from here: https://github.com/gin-gonic/gin/issues/2795
+// I don't care about linter warnings below this line
+
+// nolint: goconst,gosimple
+func parseHTMLFromEmbedFS(embedFS embed.FS, pattern string) *template.Template {
+	root := template.New("")
+	return template.Must(root, loadAndAddToRoot(template.FuncMap{}, root, embedFS, pattern))
+}
+
+// nolint: goconst,gosimple
+func loadAndAddToRoot(funcMap template.FuncMap, rootTemplate *template.Template, embedFS embed.FS, pattern string) error {
+	pattern = strings.ReplaceAll(pattern, ".", "\\.")
+	pattern = strings.ReplaceAll(pattern, "*", ".*")
+
+	err := fs.WalkDir(embedFS, ".", func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if matched, _ := regexp.MatchString(pattern, path); !d.IsDir() && matched {
+			data, readErr := embedFS.ReadFile(path)
+			if readErr != nil {
+				return readErr
+			}
+			t := rootTemplate.New(path).Funcs(funcMap)
+			if _, parseErr := t.Parse(string(data)); parseErr != nil {
+				return parseErr
+			}
+		}
+		return nil
+	})
+	return err
+}
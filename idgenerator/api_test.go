@@ -1,10 +1,10 @@
-package main_test
+package idgenerator_test
 
 import (
 	"encoding/json"
 	"github.com/corbym/gocrest/is"
 	"github.com/corbym/gocrest/then"
-	"github.com/hochfrequenz/malo-id-generator/cmd"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
 	"github.com/stretchr/testify/suite"
 	"io"
 	"net/http"
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 type Suite struct {
@@ -48,7 +49,7 @@ func performRequest(r http.Handler, method, path string, body io.Reader) *httpte
 func (s *Suite) Test_Endpoint_Fails_Without_An_Environment_Variable() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "foobar") // set an unsupported value
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotImplemented))
 	responseBody := response.Body.String()
@@ -59,7 +60,7 @@ func (s *Suite) Test_MaLo_Endpoint_Returns_Something_Like_A_MaLo() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "malo")
 	then.AssertThat(s.T(), err, is.Nil())
 	maloPattern := regexp.MustCompile(`<span class="malo-id">\d{10}</span><span class="checksum" [^>]+>\d</span>`)
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	responseBody := response.Body.String()
@@ -69,7 +70,7 @@ func (s *Suite) Test_MaLo_Endpoint_Returns_Something_Like_A_MaLo() {
 func (s *Suite) Test_MaLo_Json_Endpoint() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "malo")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/json")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	var jsonResponse JsonResponse
@@ -82,7 +83,7 @@ func (s *Suite) Test_NeLo_Endpoint_Returns_Something_Like_A_NeLo() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "nelo")
 	then.AssertThat(s.T(), err, is.Nil())
 	neloPattern := regexp.MustCompile(`<span class="nelo-id">E[A-Z\d]{9}</span><span class="checksum" [^>]+>\d</span>`)
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	responseBody := response.Body.String()
@@ -92,7 +93,7 @@ func (s *Suite) Test_NeLo_Endpoint_Returns_Something_Like_A_NeLo() {
 func (s *Suite) Test_NeLo_Json_Endpoint() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "nelo")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/json")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	var jsonResponse JsonResponse
@@ -105,7 +106,7 @@ func (s *Suite) Test_NeLo_Json_Endpoint() {
 func (s *Suite) Test_MeLo_Endpoint_Returns_Something_Like_A_MeLo() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "melo")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	responseBody := response.Body.String()
@@ -117,7 +118,7 @@ func (s *Suite) Test_MeLo_Endpoint_Returns_Something_Like_A_MeLo() {
 func (s *Suite) Test_MeLo_Json_Endpoint() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "melo")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/json")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	var jsonResponse JsonResponse
@@ -131,7 +132,7 @@ func (s *Suite) Test_TRID_Endpoint_Returns_Something_Like_A_TRID() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "trid")
 	then.AssertThat(s.T(), err, is.Nil())
 	tridPattern := regexp.MustCompile(`<span class="tr-id">D[A-Z\d]{9}</span><span class="checksum" [^>]+>\d</span>`)
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	responseBody := response.Body.String()
@@ -141,7 +142,7 @@ func (s *Suite) Test_TRID_Endpoint_Returns_Something_Like_A_TRID() {
 func (s *Suite) Test_TR_Json_Endpoint() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "trid")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/json")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	var jsonResponse JsonResponse
@@ -155,7 +156,7 @@ func (s *Suite) Test_SRID_Endpoint_Returns_Something_Like_A_SRID() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "srid")
 	then.AssertThat(s.T(), err, is.Nil())
 	sridPattern := regexp.MustCompile(`<span class="sr-id">C[A-Z\d]{9}</span><span class="checksum" [^>]+>\d</span>`)
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	responseBody := response.Body.String()
@@ -165,7 +166,7 @@ func (s *Suite) Test_SRID_Endpoint_Returns_Something_Like_A_SRID() {
 func (s *Suite) Test_SR_Json_Endpoint() {
 	err := os.Setenv("ID_TYPE_TO_GENERATE", "srid")
 	then.AssertThat(s.T(), err, is.Nil())
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/json")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 	var jsonResponse JsonResponse
@@ -175,27 +176,35 @@ func (s *Suite) Test_SR_Json_Endpoint() {
 	then.AssertThat(s.T(), jsonResponse.Id[0:1], is.EqualTo("C"))
 }
 func (s *Suite) Test_Stylesheet_Is_Returned() {
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/style")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 }
-func (s *Suite) Test_HfStylesheet_Is_Returned() {
-	router := main.NewRouter()
-	response := performGetRequest(router, "/hfstyle")
-	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+
+// companyAssets are stand-ins for the files of the companystylesheet submodule, which the binary passes to the routes
+var companyAssets = fstest.MapFS{
+	"css/hochfrequenz.css":            {Data: []byte("body { font-family: Roboto; }")},
+	"fonts/Roboto/Roboto-Regular.ttf": {Data: []byte("regular")},
+	"logo_weiss.png":                  {Data: []byte("logo")},
+	"symbol_weiss.png":                {Data: []byte("symbol")},
 }
-func (s *Suite) Test_Logo_Is_Returned() {
-	router := main.NewRouter()
-	response := performGetRequest(router, "/logo")
-	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+
+func (s *Suite) Test_Company_Assets_Are_Returned() {
+	handler := idgenerator.NewHandler("", idgenerator.RouteOptions{CompanyAssets: companyAssets})
+	for path, contentType := range map[string]string{"/hfstyle": "text/css", "/roboto-regular": "font/ttf", "/logo": "image/png", "/symbol": "image/png"} {
+		response := performGetRequest(handler, path)
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+		then.AssertThat(s.T(), response.Header().Get("Content-Type"), is.EqualTo(contentType))
+	}
+	response := performGetRequest(handler, "/roboto-bold") // not in the assets
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
 }
-func (s *Suite) Test_Symbol_Is_Returned() {
-	router := main.NewRouter()
-	response := performGetRequest(router, "/symbol")
-	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+func (s *Suite) Test_Company_Assets_Are_Optional() {
+	response := performGetRequest(idgenerator.NewRouter(), "/logo")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
 }
 func (s *Suite) Test_Favicon_Is_Returned() {
-	router := main.NewRouter()
+	router := idgenerator.NewRouter()
 	response := performGetRequest(router, "/favicon")
 	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
 }
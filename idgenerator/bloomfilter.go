@@ -0,0 +1,119 @@
+package idgenerator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// bloomFilterMagic is the beginning of every serialized BloomFilter (followed by the version number)
+var bloomFilterMagic = []byte("MALOBLOOM")
+
+const bloomFilterVersion byte = 1
+
+// A BloomFilter is a compact, probabilistic set of IDs: Contains is always true for IDs that were added and rarely (with the configured false positive rate) true for others.
+// Note that it does not hide the IDs: the ID spaces are small enough to test every possible ID against the filter, so treat a filter like the list it was built from.
+type BloomFilter struct {
+	bits      []uint64
+	bitCount  uint64 // bitCount is the number of bits (m)
+	hashCount uint32 // hashCount is the number of hash functions (k)
+	itemCount uint64 // itemCount is the number of added IDs (n)
+}
+
+// NewBloomFilter returns an empty BloomFilter that is sized for expectedItems IDs with the given false positive rate
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) (*BloomFilter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("the false positive rate has to be between 0 and 1 (exclusive) but was %g", falsePositiveRate)
+	}
+	n := math.Max(float64(expectedItems), 1)
+	// see https://en.wikipedia.org/wiki/Bloom_filter#Optimal_number_of_hash_functions
+	bitCount := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashCount := uint32(math.Max(1, math.Round(float64(bitCount)/n*math.Ln2)))
+	return &BloomFilter{bits: make([]uint64, (bitCount+63)/64), bitCount: bitCount, hashCount: hashCount}, nil
+}
+
+// positions returns the k bit positions of the id (using double hashing of a SHA-256 digest, so that the positions don't depend on the platform)
+func (f *BloomFilter) positions(id string) []uint64 {
+	digest := sha256.Sum256([]byte(id))
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	result := make([]uint64, f.hashCount)
+	for i := range result {
+		result[i] = (h1 + uint64(i)*h2) % f.bitCount
+	}
+	return result
+}
+
+// Add adds the id to the filter
+func (f *BloomFilter) Add(id string) {
+	for _, position := range f.positions(id) {
+		f.bits[position/64] |= 1 << (position % 64)
+	}
+	f.itemCount++
+}
+
+// Contains returns true if the id was (probably) added to the filter
+func (f *BloomFilter) Contains(id string) bool {
+	for _, position := range f.positions(id) {
+		if f.bits[position/64]&(1<<(position%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of IDs that were added to the filter
+func (f *BloomFilter) Len() int {
+	return int(f.itemCount)
+}
+
+// FalsePositiveRate returns the expected false positive rate for the number of IDs that were added to the filter
+func (f *BloomFilter) FalsePositiveRate() float64 {
+	k, m, n := float64(f.hashCount), float64(f.bitCount), float64(f.itemCount)
+	return math.Pow(1-math.Exp(-k*n/m), k)
+}
+
+// WriteTo writes the serialized filter to w
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+	buffer.Write(bloomFilterMagic)
+	buffer.WriteByte(bloomFilterVersion)
+	_ = binary.Write(&buffer, binary.BigEndian, f.bitCount)
+	_ = binary.Write(&buffer, binary.BigEndian, f.hashCount)
+	_ = binary.Write(&buffer, binary.BigEndian, f.itemCount)
+	_ = binary.Write(&buffer, binary.BigEndian, f.bits)
+	return buffer.WriteTo(w)
+}
+
+// isSerializedBloomFilter returns true if data starts like a serialized BloomFilter
+func isSerializedBloomFilter(data []byte) bool {
+	return bytes.HasPrefix(data, bloomFilterMagic)
+}
+
+// ReadBloomFilter reads a filter that was serialized with WriteTo
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomFilterMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil || !isSerializedBloomFilter(header) {
+		return nil, fmt.Errorf("the data is no serialized bloom filter")
+	}
+	if header[len(header)-1] != bloomFilterVersion {
+		return nil, fmt.Errorf("unsupported bloom filter version %d", header[len(header)-1])
+	}
+	filter := &BloomFilter{}
+	for _, field := range []any{&filter.bitCount, &filter.hashCount, &filter.itemCount} {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, fmt.Errorf("the bloom filter is truncated: %w", err)
+		}
+	}
+	if filter.bitCount == 0 || filter.hashCount == 0 || filter.bitCount > math.MaxInt32*64 {
+		return nil, fmt.Errorf("the bloom filter header is corrupt")
+	}
+	filter.bits = make([]uint64, (filter.bitCount+63)/64)
+	if err := binary.Read(r, binary.BigEndian, filter.bits); err != nil {
+		return nil, fmt.Errorf("the bloom filter is truncated: %w", err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,254 @@
+package idgenerator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"html"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the environment variables that contain the secrets with which the chat requests are signed. Each chat endpoint is only available if its secret is set.
+const (
+	slackSigningSecretVariable = "SLACK_SIGNING_SECRET"
+	teamsWebhookSecretVariable = "TEAMS_WEBHOOK_SECRET" // the security token that Teams shows when the outgoing webhook is created (base64)
+)
+
+// maxChatCount is the maximum number of IDs per chat command
+const maxChatCount = 50
+
+// maxChatRequestSize is the maximum size of a chat request; Slack and Teams send far less, so larger bodies are rejected before their signature is checked
+const maxChatRequestSize = 1 << 20
+
+// slackLinesPerSection is the number of lines per section block of a Slack reply (the text of a section is limited to 3000 characters)
+const slackLinesPerSection = 10
+
+// maxSlackRequestAge is how old a Slack request may be; older requests are rejected to prevent replay attacks
+const maxSlackRequestAge = 5 * time.Minute
+
+// chatMentionPattern matches the mention of the webhook (e.g. "<at>IdBot</at>") and other HTML tags in Teams messages
+var chatMentionPattern = regexp.MustCompile(`<at>[^<]*</at>|<[^>]+>`)
+
+// A chatReply is the answer to a chat command, independent of the chat's message format
+type chatReply struct {
+	Title string
+	Lines []string // Lines are markdown, e.g. "`41373559241`"
+}
+
+// chatUsage describes the chat commands
+const chatUsage = "Usage: `malo 5` (or `nelo`, `melo`, `trid`, `srid`) generates 5 new IDs; `validate 41373559241 E1137355921` validates IDs; `help` shows this message."
+
+// runChatCommand executes the command in words, e.g. ["malo", "5"] or ["validate", "E1137355921"]. If the first word is not a command (e.g. the name of a generic slash command like "/ids malo 5"), it's skipped.
+func runChatCommand(words []string) chatReply {
+	if len(words) > 0 && !isChatCommand(words[0]) {
+		words = words[1:]
+	}
+	if len(words) == 0 || strings.EqualFold(words[0], "help") {
+		return chatReply{Title: "malo-id-generator", Lines: []string{chatUsage}}
+	}
+	if strings.EqualFold(words[0], "validate") {
+		return validateChatCommand(words[1:])
+	}
+	idType, err := getIdType(words[0])
+	if err != nil {
+		return chatReply{Title: fmt.Sprintf("Unknown command '%s'", words[0]), Lines: []string{chatUsage}}
+	}
+	count := 1
+	if len(words) > 1 {
+		if count, err = strconv.Atoi(words[1]); err != nil || count < 1 || count > maxChatCount {
+			return chatReply{Title: "Invalid count", Lines: []string{fmt.Sprintf("The count has to be between 1 and %d but was '%s'.", maxChatCount, words[1])}}
+		}
+	}
+	generator := idType.Generator()
+	reply := chatReply{Title: fmt.Sprintf("%d new %s-ID(s)", count, idType.Label)}
+	for i := 0; i < count; i++ {
+		rawId, generationErr := generateUnusedIdDictionary(generator)
+		if generationErr != nil {
+			return chatReply{Title: "Generation failed", Lines: []string{generationErr.Error()}}
+		}
+		reply.Lines = append(reply.Lines, "`"+rawId["id"]+"`")
+	}
+	return reply
+}
+
+// isChatCommand returns true if the word is one of the commands of runChatCommand
+func isChatCommand(word string) bool {
+	if strings.EqualFold(word, "help") || strings.EqualFold(word, "validate") {
+		return true
+	}
+	_, err := getIdType(word)
+	return err == nil
+}
+
+// validateChatCommand validates each of the values
+func validateChatCommand(values []string) chatReply {
+	if len(values) == 0 {
+		return chatReply{Title: "Nothing to validate", Lines: []string{chatUsage}}
+	}
+	if len(values) > maxChatCount {
+		return chatReply{Title: "Too many values", Lines: []string{fmt.Sprintf("At most %d values can be validated at once.", maxChatCount)}}
+	}
+	reply := chatReply{Title: "Validation"}
+	for _, value := range values {
+		result := ValidateValue(nil, value)
+		switch {
+		case result.Valid:
+			reply.Lines = append(reply.Lines, fmt.Sprintf("✅ `%s` is a valid %s-ID", result.Value, result.Type))
+		case result.CorrectedId != "":
+			reply.Lines = append(reply.Lines, fmt.Sprintf("❌ `%s`: %s. Did you mean `%s`?", result.Value, result.Reason, result.CorrectedId))
+		default:
+			reply.Lines = append(reply.Lines, fmt.Sprintf("❌ `%s`: %s", result.Value, result.Reason))
+		}
+	}
+	return reply
+}
+
+// getChatSecret returns the secret from the environment variable or writes an error response and returns false
+func getChatSecret(c *gin.Context, variable string) (string, bool) {
+	secret, ok := os.LookupEnv(variable)
+	if !ok || secret == "" {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": fmt.Sprintf("no value set for environment variable '%s'", variable)})
+		return "", false
+	}
+	return secret, true
+}
+
+// verifySlackSignature checks the signature of a Slack request (see https://api.slack.com/authentication/verifying-requests-from-slack)
+func verifySlackSignature(secret string, timestamp string, signature string, body []byte, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp '%s'", timestamp)
+	}
+	if math.Abs(now.Sub(time.Unix(seconds, 0)).Seconds()) > maxSlackRequestAge.Seconds() {
+		return fmt.Errorf("the request timestamp is more than %s off", maxSlackRequestAge)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// readChatBody returns the body of the chat request or writes an error response and returns false. The body is limited to maxChatRequestSize.
+func readChatBody(c *gin.Context) ([]byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxChatRequestSize)
+	body, err := c.GetRawData()
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("the request must not be larger than %d bytes", maxChatRequestSize)})
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return body, true
+}
+
+// slackHandler answers Slack slash commands, e.g. "/malo 5" or "/validate E1137355921", with a Block Kit message that only the requesting user sees
+func slackHandler(c *gin.Context) {
+	secret, ok := getChatSecret(c, slackSigningSecretVariable)
+	if !ok {
+		return
+	}
+	body, ok := readChatBody(c)
+	if !ok {
+		return
+	}
+	if err := verifySlackSignature(secret, c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body, time.Now()); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	words := append([]string{strings.TrimPrefix(form.Get("command"), "/")}, strings.Fields(form.Get("text"))...)
+	reply := runChatCommand(words)
+	blocks := []gin.H{{"type": "header", "text": gin.H{"type": "plain_text", "text": reply.Title}}}
+	for start := 0; start < len(reply.Lines); start += slackLinesPerSection {
+		end := min(start+slackLinesPerSection, len(reply.Lines))
+		blocks = append(blocks, gin.H{"type": "section", "text": gin.H{"type": "mrkdwn", "text": strings.Join(reply.Lines[start:end], "\n")}})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"response_type": "ephemeral",
+		"text":          "*" + reply.Title + "*\n" + strings.Join(reply.Lines, "\n"),
+		"blocks":        blocks,
+	})
+}
+
+// verifyTeamsSignature checks the HMAC of a Teams outgoing webhook request (see https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/add-outgoing-webhook)
+func verifyTeamsSignature(secret string, authorization string, body []byte) error {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return fmt.Errorf("the value of '%s' is not base64 encoded", teamsWebhookSecretVariable)
+	}
+	signature, ok := strings.CutPrefix(authorization, "HMAC ")
+	if !ok {
+		return fmt.Errorf("the Authorization header has to start with 'HMAC '")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	if !hmac.Equal([]byte(base64.StdEncoding.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// teamsHandler answers messages to a Teams outgoing webhook, e.g. "@IdBot malo 5", with an Adaptive Card
+func teamsHandler(c *gin.Context) {
+	secret, ok := getChatSecret(c, teamsWebhookSecretVariable)
+	if !ok {
+		return
+	}
+	body, ok := readChatBody(c)
+	if !ok {
+		return
+	}
+	if err := verifyTeamsSignature(secret, c.GetHeader("Authorization"), body); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	var activity struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &activity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	text := html.UnescapeString(chatMentionPattern.ReplaceAllString(activity.Text, " "))
+	reply := runChatCommand(strings.Fields(strings.TrimPrefix(strings.TrimSpace(text), "/")))
+	cardBody := []gin.H{{"type": "TextBlock", "text": reply.Title, "weight": "Bolder", "size": "Medium"}}
+	for _, line := range reply.Lines {
+		cardBody = append(cardBody, gin.H{"type": "TextBlock", "text": line, "wrap": true})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"type": "message",
+		"text": "**" + reply.Title + "**\n\n" + strings.Join(reply.Lines, "\n\n"),
+		"attachments": []gin.H{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": gin.H{
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"body":    cardBody,
+			},
+		}},
+	})
+}
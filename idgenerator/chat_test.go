@@ -0,0 +1,144 @@
+package idgenerator_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const slackTestSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// performSlackRequest sends a signed slash command to /chat/slack
+func (s *Suite) performSlackRequest(command string, text string, timestamp time.Time, secret string) *httptest.ResponseRecorder {
+	s.T().Setenv("SLACK_SIGNING_SECRET", slackTestSecret)
+	body := url.Values{"command": {command}, "text": {text}, "user_name": {"tester"}}.Encode()
+	unixTimestamp := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + unixTimestamp + ":" + body))
+	request, _ := http.NewRequest("POST", "/chat/slack", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("X-Slack-Request-Timestamp", unixTimestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	response := httptest.NewRecorder()
+	idgenerator.NewRouter().ServeHTTP(response, request)
+	return response
+}
+
+type slackTestResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+	Blocks       []struct {
+		Type string `json:"type"`
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"blocks"`
+}
+
+func (s *Suite) Test_Slack_Generates_Ids() {
+	response := s.performSlackRequest("/malo", "5", time.Now(), slackTestSecret)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var reply slackTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &reply), is.Nil())
+	then.AssertThat(s.T(), reply.ResponseType, is.EqualTo("ephemeral"))
+	then.AssertThat(s.T(), len(reply.Blocks), is.EqualTo(2))
+	lines := strings.Split(reply.Blocks[1].Text.Text, "\n")
+	then.AssertThat(s.T(), len(lines), is.EqualTo(5))
+	for _, line := range lines {
+		then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(strings.Trim(line, "`")), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Slack_Validates_Ids_With_A_Generic_Command() {
+	response := s.performSlackRequest("/ids", "validate 41373559241 41373559240", time.Now(), slackTestSecret)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var reply slackTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &reply), is.Nil())
+	then.AssertThat(s.T(), reply.Text, is.StringContaining("✅ `41373559241` is a valid MaLo-ID"))
+	then.AssertThat(s.T(), reply.Text, is.StringContaining("Did you mean `41373559241`?"))
+}
+
+func (s *Suite) Test_Slack_Rejects_Invalid_Signatures() {
+	response := s.performSlackRequest("/malo", "1", time.Now(), "wrong secret")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusUnauthorized))
+	response = s.performSlackRequest("/malo", "1", time.Now().Add(-10*time.Minute), slackTestSecret) // replayed
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusUnauthorized))
+}
+
+func (s *Suite) Test_Chat_Is_Not_Available_Without_Secret() {
+	s.T().Setenv("SLACK_SIGNING_SECRET", "")
+	s.T().Setenv("TEAMS_WEBHOOK_SECRET", "")
+	for _, path := range []string{"/chat/slack", "/chat/teams"} {
+		response := performRequest(idgenerator.NewRouter(), "POST", path, strings.NewReader("{}"))
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotImplemented))
+	}
+}
+
+// performTeamsRequest sends a signed outgoing webhook message to /chat/teams
+func (s *Suite) performTeamsRequest(text string, signed bool) *httptest.ResponseRecorder {
+	secret := base64.StdEncoding.EncodeToString([]byte("teams test secret"))
+	s.T().Setenv("TEAMS_WEBHOOK_SECRET", secret)
+	body, _ := json.Marshal(map[string]any{"type": "message", "text": text, "from": map[string]string{"name": "Tester"}})
+	mac := hmac.New(sha256.New, []byte("teams test secret"))
+	if signed {
+		mac.Write(body)
+	}
+	request, _ := http.NewRequest("POST", "/chat/teams", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	response := httptest.NewRecorder()
+	idgenerator.NewRouter().ServeHTTP(response, request)
+	return response
+}
+
+func (s *Suite) Test_Teams_Replies_With_An_Adaptive_Card() {
+	response := s.performTeamsRequest("<at>IdBot</at>&nbsp;nelo 3", true)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var reply struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string `json:"type"`
+				Body []struct {
+					Text string `json:"text"`
+				} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &reply), is.Nil())
+	then.AssertThat(s.T(), reply.Type, is.EqualTo("message"))
+	card := reply.Attachments[0]
+	then.AssertThat(s.T(), card.ContentType, is.EqualTo("application/vnd.microsoft.card.adaptive"))
+	then.AssertThat(s.T(), card.Content.Type, is.EqualTo("AdaptiveCard"))
+	then.AssertThat(s.T(), len(card.Content.Body), is.EqualTo(4)) // title + 3 IDs
+	for _, block := range card.Content.Body[1:] {
+		then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(strings.Trim(block.Text, "`")), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Teams_Rejects_Invalid_Signatures() {
+	response := s.performTeamsRequest("malo", false)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusUnauthorized))
+}
+
+func (s *Suite) Test_Chat_Rejects_Too_Large_Requests() {
+	s.T().Setenv("SLACK_SIGNING_SECRET", slackTestSecret)
+	s.T().Setenv("TEAMS_WEBHOOK_SECRET", base64.StdEncoding.EncodeToString([]byte("teams test secret")))
+	for _, path := range []string{"/chat/slack", "/chat/teams"} {
+		// the body is rejected before its signature is checked
+		response := performRequest(idgenerator.NewRouter(), "POST", path, strings.NewReader(strings.Repeat("a", 2<<20)))
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusRequestEntityTooLarge))
+	}
+}
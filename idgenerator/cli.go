@@ -0,0 +1,77 @@
+package idgenerator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+// A cliCommand is a subcommand of the binary. If the binary is started with arguments (e.g. "./api analyze 41373559241"), it runs the respective command instead of the HTTP server.
+type cliCommand struct {
+	description string
+	run         func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// cliCommands maps the name of each subcommand to its implementation
+var cliCommands = map[string]cliCommand{
+	"analyze":  {description: "reports which typos of an ID the checksum detects (or aggregated detection rates for random IDs with -sample)", run: analyzeCommand},
+	"bloom":    {description: "builds a compact probabilistic exclusion list (bloom filter) from the IDs in CSV or text files (or stdin)", run: bloomCommand},
+	"extract":  {description: "finds all MaLo, MeLo, NeLo, TR and SR IDs in text or EDIFACT files (or stdin) and validates them", run: extractCommand},
+	"invoke":   {description: "runs an Azure Functions invocation payload (e.g. a recorded queue, timer or HTTP trigger) locally and prints the response", run: invokeCommand},
+	"rewrite":  {description: "replaces the IDs in a CSV, EDIFACT or JSON file (or stdin) consistently with pseudonyms and exports the mapping table", run: rewriteCommand},
+	"rpc":      {description: "serves the JSON-RPC 2.0 tools (generate, validate, explain, checksum) on stdin/stdout, one request per line", run: rpcCommand},
+	"sequence": {description: "prints the n-th IDs of the keyed, collision-free sequence of a type (or the counter of an ID)", run: sequenceCommand},
+	"validate": {description: "validates the IDs from CSV or newline separated text files (or stdin) and prints a per-row report", run: validateCommand},
+}
+
+// RunCli runs the subcommand given in args[0] and returns the exit code of the process
+func RunCli(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printCliUsage(stderr)
+		return 2
+	}
+	// the generators log every generated ID for the Azure log stream; on the command line this would only clutter the output
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown command '%s'\n", args[0])
+		printCliUsage(stderr)
+		return 2
+	}
+	if err := command.run(args[1:], stdin, stdout); err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func printCliUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: api [command] [flags] [arguments]. Without a command, the HTTP server is started. Available commands:")
+	names := make([]string, 0, len(cliCommands))
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "  %-12s %s\n", name, cliCommands[name].description)
+	}
+}
+
+// newFlagSet returns a flag set for the given subcommand that returns errors instead of exiting the process
+func newFlagSet(name string, output io.Writer) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(output)
+	return flagSet
+}
+
+// writeJson writes value as indented JSON to w
+func writeJson(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
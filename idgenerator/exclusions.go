@@ -0,0 +1,205 @@
+package idgenerator
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// exclusionListPathVariable is the environment variable that points to the exclusion list: either a text/CSV file with one ID per line (in the first column) or a bloom filter that was built with the "bloom" command
+const exclusionListPathVariable = "EXCLUSION_LIST_PATH"
+
+// ExclusionStatistics count how often the generators checked a candidate against the exclusion list and how often it was rejected
+type ExclusionStatistics struct {
+	Checked  int64 `json:"checked"`
+	Rejected int64 `json:"rejected"`
+}
+
+// An ExclusionList contains (real) IDs that must never be generated
+type ExclusionList struct {
+	ids    map[string]bool // ids is used for plain lists
+	filter *BloomFilter    // filter is used instead of ids for probabilistic lists
+	mutex  sync.Mutex
+	// statistics are the ExclusionStatistics per ID type (label)
+	statistics map[string]*ExclusionStatistics
+}
+
+// NewExclusionList returns an exclusion list that contains exactly the given IDs
+func NewExclusionList(ids []string) *ExclusionList {
+	list := &ExclusionList{ids: make(map[string]bool, len(ids)), statistics: map[string]*ExclusionStatistics{}}
+	for _, id := range ids {
+		list.ids[strings.ToUpper(strings.TrimSpace(id))] = true
+	}
+	return list
+}
+
+// NewProbabilisticExclusionList returns an exclusion list that contains the IDs of the filter (and, rarely, false positives; those are simply not generated either)
+func NewProbabilisticExclusionList(filter *BloomFilter) *ExclusionList {
+	return &ExclusionList{filter: filter, statistics: map[string]*ExclusionStatistics{}}
+}
+
+// ReadExclusionList reads a serialized BloomFilter or a text/CSV file with one ID per line (in the first column)
+func ReadExclusionList(r io.Reader) (*ExclusionList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if isSerializedBloomFilter(data) {
+		filter, filterErr := ReadBloomFilter(bytes.NewReader(data))
+		if filterErr != nil {
+			return nil, filterErr
+		}
+		return NewProbabilisticExclusionList(filter), nil
+	}
+	values, err := readBulkValues(bytes.NewReader(data), bulkInputOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, value.value)
+	}
+	return NewExclusionList(ids), nil
+}
+
+// Contains returns true if the id is (probably, for probabilistic lists) on the list
+func (l *ExclusionList) Contains(id string) bool {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if l.filter != nil {
+		return l.filter.Contains(id)
+	}
+	return l.ids[id]
+}
+
+// rejects returns true if the generated id of the given type is on the list and updates the statistics. It's safe to call on a nil list (which rejects nothing).
+func (l *ExclusionList) rejects(typeLabel string, id string) bool {
+	if l == nil {
+		return false
+	}
+	rejected := l.Contains(id)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	statistics, ok := l.statistics[typeLabel]
+	if !ok {
+		statistics = &ExclusionStatistics{}
+		l.statistics[typeLabel] = statistics
+	}
+	statistics.Checked++
+	if rejected {
+		statistics.Rejected++
+	}
+	return rejected
+}
+
+// ExclusionListSummary describes the configured exclusion list and how often it rejected generated IDs
+type ExclusionListSummary struct {
+	Probabilistic     bool                           `json:"probabilistic"`
+	Size              int                            `json:"size"`                        // Size is the number of IDs on the list
+	FalsePositiveRate float64                        `json:"falsePositiveRate,omitempty"` // FalsePositiveRate is the expected rate of IDs that are rejected although they're not on the (probabilistic) list
+	Statistics        map[string]ExclusionStatistics `json:"statistics"`                  // Statistics per ID type
+}
+
+// Summary returns the summary of the list and a snapshot of its statistics
+func (l *ExclusionList) Summary() ExclusionListSummary {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	summary := ExclusionListSummary{Size: len(l.ids), Statistics: map[string]ExclusionStatistics{}}
+	if l.filter != nil {
+		summary.Probabilistic = true
+		summary.Size = l.filter.Len()
+		summary.FalsePositiveRate = l.filter.FalsePositiveRate()
+	}
+	for typeLabel, statistics := range l.statistics {
+		summary.Statistics[typeLabel] = *statistics
+	}
+	return summary
+}
+
+var (
+	exclusionListsMutex sync.Mutex
+	// exclusionLists contains the lists that were already loaded, by their path, so that each file is only read once per process
+	exclusionLists = map[string]*ExclusionList{}
+)
+
+// getExclusionList returns the list configured in the EXCLUSION_LIST_PATH environment variable or nil if the variable is not set
+func getExclusionList() (*ExclusionList, error) {
+	path, ok := os.LookupEnv(exclusionListPathVariable)
+	if !ok || path == "" {
+		return nil, nil
+	}
+	exclusionListsMutex.Lock()
+	defer exclusionListsMutex.Unlock()
+	if list, ok := exclusionLists[path]; ok {
+		return list, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the exclusion list: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	list, err := ReadExclusionList(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the exclusion list '%s': %w", path, err)
+	}
+	exclusionLists[path] = list
+	return list, nil
+}
+
+// exclusionsHandler returns the summary of the configured exclusion list
+func exclusionsHandler(c *gin.Context) {
+	list, err := getExclusionList()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if list == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": fmt.Sprintf("no value set for environment variable '%s'; no IDs are excluded", exclusionListPathVariable)})
+		return
+	}
+	c.JSON(http.StatusOK, list.Summary())
+}
+
+// bloomCommand builds a bloom filter from the IDs in the given files (or stdin) that can be used as a compact exclusion list
+func bloomCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("bloom", stdout)
+	falsePositiveRate := flagSet.Float64("fp", 0.001, "the false positive rate, i.e. the share of other IDs that are rejected, too")
+	column := flagSet.String("column", "", "the CSV column that contains the IDs (name or 1-based index; default: first column)")
+	output := flagSet.String("output", "", "the file to write the filter to (default: stdout)")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	files, err := openInputFiles(flagSet.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, file := range files {
+		values, readErr := readBulkValues(file, bulkInputOptions{column: *column})
+		_ = file.Close()
+		if readErr != nil {
+			return readErr
+		}
+		for _, value := range values {
+			ids = append(ids, strings.ToUpper(value.value))
+		}
+	}
+	filter, err := NewBloomFilter(len(ids), *falsePositiveRate)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		filter.Add(id)
+	}
+	if *output == "" {
+		_, err = filter.WriteTo(stdout)
+		return err
+	}
+	var serialized bytes.Buffer
+	_, _ = filter.WriteTo(&serialized)
+	return os.WriteFile(*output, serialized.Bytes(), 0o600)
+}
@@ -0,0 +1,106 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (s *Suite) Test_Exclusion_List_From_Csv() {
+	list, err := idgenerator.ReadExclusionList(strings.NewReader("MaLo;Kunde\n41373559241;Müller\ne1137355921;Meier\n"))
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), list.Contains("41373559241"), is.True())
+	then.AssertThat(s.T(), list.Contains("E1137355921"), is.True())
+	then.AssertThat(s.T(), list.Contains("51238696781"), is.False())
+	then.AssertThat(s.T(), list.Summary().Size, is.EqualTo(2))
+}
+
+func (s *Suite) Test_Bloom_Filter_Round_Trip() {
+	filter, err := idgenerator.NewBloomFilter(1000, 0.01)
+	then.AssertThat(s.T(), err, is.Nil())
+	var added []string
+	for i := 0; i < 1000; i++ {
+		id, _ := idgenerator.MaLoIdType.NewRandomId()
+		filter.Add(id)
+		added = append(added, id)
+	}
+	var serialized bytes.Buffer
+	_, err = filter.WriteTo(&serialized)
+	then.AssertThat(s.T(), err, is.Nil())
+	list, err := idgenerator.ReadExclusionList(&serialized)
+	then.AssertThat(s.T(), err, is.Nil())
+	for _, id := range added {
+		then.AssertThat(s.T(), list.Contains(id), is.True())
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		id, _ := idgenerator.NeLoIdType.NewRandomId()
+		if list.Contains(id) {
+			falsePositives++
+		}
+	}
+	then.AssertThat(s.T(), falsePositives, is.LessThan(50)) // expected: ~10
+	summary := list.Summary()
+	then.AssertThat(s.T(), summary.Probabilistic, is.True())
+	then.AssertThat(s.T(), summary.Size, is.EqualTo(1000))
+	then.AssertThat(s.T(), summary.FalsePositiveRate, is.LessThan(0.02))
+}
+
+func (s *Suite) Test_Generators_Regenerate_Excluded_Ids() {
+	// a tiny filter with a single ID rejects about half of all IDs
+	filter, _ := idgenerator.NewBloomFilter(1, 0.5)
+	filter.Add("41373559241")
+	path := filepath.Join(s.T().TempDir(), "exclusions.bloom")
+	var serialized bytes.Buffer
+	_, _ = filter.WriteTo(&serialized)
+	then.AssertThat(s.T(), os.WriteFile(path, serialized.Bytes(), 0o600), is.Nil())
+	s.T().Setenv("EXCLUSION_LIST_PATH", path)
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	router := idgenerator.NewRouter()
+	for i := 0; i < 20; i++ {
+		response := performGetRequest(router, "/json")
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+		var jsonResponse JsonResponse
+		_ = json.Unmarshal(response.Body.Bytes(), &jsonResponse)
+		then.AssertThat(s.T(), filter.Contains(jsonResponse.Id), is.False())
+
+		id, err := idgenerator.MaLoIdType.NewRandomId()
+		then.AssertThat(s.T(), err, is.Nil())
+		then.AssertThat(s.T(), filter.Contains(id), is.False())
+
+		response = performGetRequest(router, "/explain?format=json")
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+		var explanation idgenerator.ChecksumExplanation
+		_ = json.Unmarshal(response.Body.Bytes(), &explanation)
+		then.AssertThat(s.T(), filter.Contains(explanation.Id), is.False())
+	}
+	response := performGetRequest(router, "/exclusions")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var summary idgenerator.ExclusionListSummary
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &summary), is.Nil())
+	then.AssertThat(s.T(), summary.Statistics["MaLo"].Rejected, is.GreaterThan(int64(0)))
+	then.AssertThat(s.T(), summary.Statistics["MaLo"].Checked, is.EqualTo(3*20+summary.Statistics["MaLo"].Rejected))
+}
+
+func (s *Suite) Test_Bloom_Command() {
+	directory := s.T().TempDir()
+	input := filepath.Join(directory, "production.csv")
+	_ = os.WriteFile(input, []byte("Marktlokation\n41373559241\n51238696781\n"), 0o600)
+	output := filepath.Join(directory, "exclusions.bloom")
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"bloom", "-column", "Marktlokation", "-fp", "0.0001", "-output", output, input}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	file, err := os.Open(output)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = file.Close() }()
+	list, err := idgenerator.ReadExclusionList(file)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), list.Contains("51238696781"), is.True())
+	then.AssertThat(s.T(), list.Summary().Size, is.EqualTo(2))
+}
@@ -0,0 +1,142 @@
+package idgenerator
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A ChecksumStep describes how a single character of an ID contributes to its checksum
+type ChecksumStep struct {
+	Position  int    `json:"position"`  // Position is 1-based because the BDEW documents start counting at 1
+	Character string `json:"character"` // Character is the character of the ID at this position
+	Value     int    `json:"value"`     // Value is the digit itself or, for letters, its ASCII code (e.g. 65 for 'A')
+	Weight    int    `json:"weight"`    // Weight is 1 for odd and 2 for even positions
+	Product   int    `json:"product"`   // Product is Value * Weight
+}
+
+// A ChecksumExplanation describes the calculation of the check digit of an ID step by step
+type ChecksumExplanation struct {
+	Type              string         `json:"type"`
+	Id                string         `json:"id"` // Id is the complete ID with the correct checksum
+	IdWithoutChecksum string         `json:"idWithoutChecksum"`
+	Steps             []ChecksumStep `json:"steps"`
+	OddSum            int            `json:"oddSum"`          // OddSum is the sum of the values on odd positions
+	EvenSum           int            `json:"evenSum"`         // EvenSum is the sum of the values on even positions (not yet multiplied by 2)
+	WeightedEvenSum   int            `json:"weightedEvenSum"` // WeightedEvenSum is 2 * EvenSum
+	Sum               int            `json:"sum"`             // Sum is OddSum + WeightedEvenSum
+	Remainder         int            `json:"remainder"`       // Remainder is Sum modulo 10
+	NextMultipleOf10  int            `json:"nextMultipleOf10"`
+	Checksum          string         `json:"checksum"`                // Checksum is the difference between Sum and the next multiple of 10 (10 becomes 0)
+	GivenChecksum     string         `json:"givenChecksum,omitempty"` // GivenChecksum is the last character of the explained ID, if a complete ID was given
+}
+
+// GivenChecksumIsCorrect returns true iff a complete ID was explained and its checksum is correct
+func (e ChecksumExplanation) GivenChecksumIsCorrect() bool {
+	return e.GivenChecksum == e.Checksum
+}
+
+// ExplainChecksum calculates the checksum of the given id step by step. The id may either be a complete ID or the ID without checksum.
+func ExplainChecksum(idType IdType, id string) (ChecksumExplanation, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !idType.HasChecksum() {
+		return ChecksumExplanation{}, fmt.Errorf("%s-IDs have no checksum", idType.Label)
+	}
+	explanation := ChecksumExplanation{Type: idType.Label}
+	switch len(id) {
+	case idType.Length:
+		explanation.GivenChecksum = id[idType.Length-1:]
+		explanation.IdWithoutChecksum = id[:idType.Length-1]
+	case idType.Length - 1:
+		explanation.IdWithoutChecksum = id
+	default:
+		return ChecksumExplanation{}, fmt.Errorf("a %s-ID must be %d (with checksum) or %d (without checksum) characters long but '%s' is %d characters long", idType.Label, idType.Length, idType.Length-1, id, len(id))
+	}
+	if !idType.pattern.MatchString(explanation.IdWithoutChecksum + "0") {
+		return ChecksumExplanation{}, fmt.Errorf("'%s' does not match the %s-ID pattern %s", explanation.IdWithoutChecksum, idType.Label, idType.pattern.String())
+	}
+	// Both the MaLo algorithm and the ASCII algorithm (NeLo, TR, SR) are the same, only that the MaLo-ID consists of digits only.
+	// See https://bdew-codes.de/Content/Files/Anwdh_2023-01-18-AWH-Identifikatoren-MaKo-Bildungsvorschrift_Version.1.0.pdf chapter 6
+	for index, character := range explanation.IdWithoutChecksum {
+		step := ChecksumStep{Position: index + 1, Character: string(character), Weight: 1}
+		if unicode.IsDigit(character) {
+			step.Value = int(character - '0')
+		} else {
+			step.Value = int(character)
+		}
+		if step.Position%2 == 0 {
+			step.Weight = 2
+			explanation.EvenSum += step.Value
+		} else {
+			explanation.OddSum += step.Value
+		}
+		step.Product = step.Value * step.Weight
+		explanation.Steps = append(explanation.Steps, step)
+	}
+	explanation.WeightedEvenSum = 2 * explanation.EvenSum
+	explanation.Sum = explanation.OddSum + explanation.WeightedEvenSum
+	explanation.Remainder = explanation.Sum % 10
+	explanation.NextMultipleOf10 = (explanation.Sum/10 + 1) * 10
+	explanation.Checksum = strconv.Itoa((10 - explanation.Remainder) % 10)
+	explanation.Id = explanation.IdWithoutChecksum + explanation.Checksum
+	// the explanation must never contradict the checksum functions that are used everywhere else
+	if expectedChecksum, err := idType.CalculateChecksum(explanation.IdWithoutChecksum); err != nil {
+		return ChecksumExplanation{}, err
+	} else if expectedChecksum != explanation.Checksum {
+		return ChecksumExplanation{}, fmt.Errorf("the explained checksum '%s' of '%s' differs from the calculated checksum '%s'", explanation.Checksum, id, expectedChecksum)
+	}
+	return explanation, nil
+}
+
+// checksumExplanationHandler renders an HTML page that explains the checksum calculation for the ID in the "id" query parameter.
+// If no ID is given, a new random ID of the type from the "type" query parameter (or the configured type) is explained.
+// With ?format=json, the ChecksumExplanation is returned as JSON instead.
+func checksumExplanationHandler(c *gin.Context) {
+	id := strings.ToUpper(strings.TrimSpace(c.Query("id")))
+	var idType IdType
+	var err error
+	switch {
+	case id != "":
+		idType, err = getRequestedIdType(c, id)
+	case c.Query("type") != "":
+		idType, err = getIdType(c.Query("type"))
+	default:
+		idType, err = getConfiguredIdTypeFor(c)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if id == "" {
+		generatedId, generationErr := generateUnusedIdDictionary(idType.Generator())
+		if generationErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": generationErr.Error()})
+			return
+		}
+		id = generatedId["id"]
+	}
+	explanation, err := ExplainChecksum(idType, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, explanation)
+		return
+	}
+	var checksumTypes []string
+	for _, supportedType := range supportedIdTypes {
+		if supportedType.HasChecksum() {
+			checksumTypes = append(checksumTypes, supportedType.Label)
+		}
+	}
+	renderHtml(c, "static/templates/explain.tmpl.html", gin.H{
+		"explanation":       explanation,
+		"checksumTypes":     checksumTypes,
+		"recruitingMessage": template.HTML(recruitingMessage),
+	})
+}
@@ -0,0 +1,76 @@
+package idgenerator_test
+
+import (
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func (s *Suite) Test_MaLo_Checksum_Explanation() {
+	// the example from the BDEW documentation
+	explanation, err := idgenerator.ExplainChecksum(idgenerator.MaLoIdType, "4137355924")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(explanation.Steps), is.EqualTo(10))
+	then.AssertThat(s.T(), explanation.OddSum, is.EqualTo(17))
+	then.AssertThat(s.T(), explanation.EvenSum, is.EqualTo(26))
+	then.AssertThat(s.T(), explanation.WeightedEvenSum, is.EqualTo(52))
+	then.AssertThat(s.T(), explanation.Sum, is.EqualTo(69))
+	then.AssertThat(s.T(), explanation.Remainder, is.EqualTo(9))
+	then.AssertThat(s.T(), explanation.Checksum, is.EqualTo("1"))
+	then.AssertThat(s.T(), explanation.Id, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), explanation.GivenChecksum, is.EqualTo(""))
+}
+
+func (s *Suite) Test_Ascii_Checksum_Explanation() {
+	// the ASCII example from the BDEW documentation starts with an "A", which is not a valid prefix, so we use an "E" (69) instead
+	explanation, err := idgenerator.ExplainChecksum(idgenerator.NeLoIdType, "E1137355920")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), explanation.Steps[0].Value, is.EqualTo(69))
+	then.AssertThat(s.T(), explanation.OddSum, is.EqualTo(91))
+	then.AssertThat(s.T(), explanation.WeightedEvenSum, is.EqualTo(28))
+	then.AssertThat(s.T(), explanation.Checksum, is.EqualTo("1"))
+	then.AssertThat(s.T(), explanation.GivenChecksum, is.EqualTo("0"))
+	then.AssertThat(s.T(), explanation.GivenChecksumIsCorrect(), is.False())
+}
+
+func (s *Suite) Test_Explanation_Agrees_With_The_Generators() {
+	for _, idType := range []idgenerator.IdType{idgenerator.MaLoIdType, idgenerator.NeLoIdType, idgenerator.TRIdType, idgenerator.SRIdType} {
+		for i := 0; i < 20; i++ {
+			id, err := idType.NewRandomId()
+			then.AssertThat(s.T(), err, is.Nil())
+			explanation, err := idgenerator.ExplainChecksum(idType, id)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), explanation.GivenChecksumIsCorrect(), is.True())
+		}
+	}
+}
+
+func (s *Suite) Test_Explanation_Page_For_A_Given_Id() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/explain?id=41373559241")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	responseBody := response.Body.String()
+	then.AssertThat(s.T(), strings.Contains(responseBody, `<span class="id-without-checksum">4137355924</span>`), is.True())
+	then.AssertThat(s.T(), strings.Contains(responseBody, "Die Prüfziffer 1 ist korrekt."), is.True())
+}
+
+func (s *Suite) Test_Explanation_Page_For_A_Fresh_Id() {
+	err := os.Setenv("ID_TYPE_TO_GENERATE", "trid")
+	then.AssertThat(s.T(), err, is.Nil())
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/explain")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), `<span class="id-without-checksum">D`), is.True())
+	response = performGetRequest(router, "/explain?type=SR")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), `<span class="id-without-checksum">C`), is.True())
+}
+
+func (s *Suite) Test_There_Is_No_Explanation_For_MeLos() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/explain?type=melo")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
@@ -0,0 +1,199 @@
+package idgenerator
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// idCandidateRegex matches everything that looks like one of the supported IDs (regardless of its checksum):
+// 33 character MeLo-IDs, 11 digit MaLo-IDs and 11 character NeLo/TR/SR-IDs (starting with E, D or C).
+var idCandidateRegex = regexp.MustCompile(`\b(?:DE[A-Z\d]{31}|[1-9]\d{10}|[CDE][A-Z\d]{9}\d)\b`)
+
+// An ExtractedId is an ID (candidate) that was found in a text
+type ExtractedId struct {
+	Id          string `json:"id"`
+	Type        string `json:"type"`
+	Valid       bool   `json:"valid"`
+	Reason      string `json:"reason,omitempty"`      // Reason describes why the ID is invalid
+	CorrectedId string `json:"correctedId,omitempty"` // CorrectedId is the ID with the correct checksum, if only the checksum is wrong
+	Offset      int    `json:"offset"`                // Offset is the 0-based byte offset of the ID in the text
+	Line        int    `json:"line"`                  // Line is the 1-based line number
+	Column      int    `json:"column"`                // Column is the 1-based (character, not byte) column in the line
+	Segment     string `json:"segment,omitempty"`     // Segment is the tag and the first data element of the surrounding EDIFACT segment, e.g. "LOC+172"
+	File        string `json:"file,omitempty"`        // File is the name of the file the ID was found in (CLI only)
+}
+
+// edifactSeparators are the special characters of an EDIFACT interchange (they may be changed in the UNA service string advice)
+type edifactSeparators struct {
+	component         byte
+	dataElement       byte
+	release           byte
+	segmentTerminator byte
+}
+
+var defaultEdifactSeparators = edifactSeparators{component: ':', dataElement: '+', release: '?', segmentTerminator: '\''}
+
+// getEdifactSeparators returns the separators if the text is an EDIFACT interchange (starts with UNA or UNB); otherwise the second return value is false
+func getEdifactSeparators(text string) (edifactSeparators, bool) {
+	text = strings.TrimLeft(text, "\ufeff \r\n\t")
+	if strings.HasPrefix(text, "UNA") && len(text) >= 9 {
+		// UNA:+.? ' defines component, data element, decimal mark, release character, reserved, segment terminator
+		return edifactSeparators{component: text[3], dataElement: text[4], release: text[6], segmentTerminator: text[8]}, true
+	}
+	if strings.HasPrefix(text, "UNB") {
+		return defaultEdifactSeparators, true
+	}
+	return edifactSeparators{}, false
+}
+
+// edifactSegmentQualifier returns the segment tag and its first data element (or component thereof), e.g. "LOC+172" for the segment starting at start
+func edifactSegmentQualifier(text string, start int, separators edifactSeparators) string {
+	segment := strings.TrimLeft(text[start:], "\r\n ")
+	tagEnd := strings.IndexByte(segment, separators.dataElement)
+	if tagEnd < 0 {
+		return ""
+	}
+	tag := segment[:tagEnd]
+	qualifier := segment[tagEnd+1:]
+	if end := strings.IndexAny(qualifier, string([]byte{separators.dataElement, separators.component, separators.segmentTerminator})); end >= 0 {
+		qualifier = qualifier[:end]
+	}
+	return tag + string(separators.dataElement) + qualifier
+}
+
+// ExtractIds finds all candidates for supported IDs in the given text and validates them. If idType is not nil, only candidates of that type are returned.
+func ExtractIds(text string, idType *IdType) []ExtractedId {
+	separators, isEdifact := getEdifactSeparators(text)
+	result := []ExtractedId{}
+	// the position (line, column and start of the EDIFACT segment) is advanced from the previous match, so that every character is only scanned once
+	line, column, segmentStart, position := 1, 1, 0, 0
+	for _, match := range idCandidateRegex.FindAllStringIndex(text, -1) {
+		for position < match[0] {
+			character, size := utf8.DecodeRuneInString(text[position:])
+			if character == '\n' {
+				line, column = line+1, 1
+			} else {
+				column++
+			}
+			if isEdifact && text[position] == separators.segmentTerminator && (position == 0 || text[position-1] != separators.release) {
+				segmentStart = position + 1
+			}
+			position += size
+		}
+		candidate := text[match[0]:match[1]]
+		detectedType, ok := detectIdType(candidate)
+		if !ok || (idType != nil && detectedType.Name != idType.Name) {
+			continue
+		}
+		validation := ValidateValue(&detectedType, candidate)
+		extractedId := ExtractedId{
+			Id:          candidate,
+			Type:        detectedType.Label,
+			Valid:       validation.Valid,
+			Reason:      validation.Reason,
+			CorrectedId: validation.CorrectedId,
+			Offset:      match[0],
+			Line:        line,
+			Column:      column,
+		}
+		if isEdifact {
+			extractedId.Segment = edifactSegmentQualifier(text, segmentStart, separators)
+		}
+		result = append(result, extractedId)
+	}
+	return result
+}
+
+// getOptionalIdType returns the IdType from the "type" query parameter or nil if it's not set
+func getOptionalIdType(c *gin.Context) (*IdType, error) {
+	typeName := c.Query("type")
+	if typeName == "" {
+		return nil, nil
+	}
+	idType, err := getIdType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	return &idType, nil
+}
+
+// filterValidIds returns only those extracted IDs that are valid
+func filterValidIds(extractedIds []ExtractedId) []ExtractedId {
+	validIds := []ExtractedId{}
+	for _, extractedId := range extractedIds {
+		if extractedId.Valid {
+			validIds = append(validIds, extractedId)
+		}
+	}
+	return validIds
+}
+
+// extractionHandler returns all ID candidates from the uploaded text (or EDIFACT) file as JSON. Use ?type= to restrict the ID type and ?valid=true to only return valid IDs.
+func extractionHandler(c *gin.Context) {
+	idType, err := getOptionalIdType(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, _, err := getUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer func() { _ = file.Close() }()
+	text, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	extractedIds := ExtractIds(string(text), idType)
+	if c.Query("valid") == "true" {
+		extractedIds = filterValidIds(extractedIds)
+	}
+	c.JSON(http.StatusOK, extractedIds)
+}
+
+// extractCommand is the CLI equivalent of the extractionHandler: it prints all ID candidates from the given files (or stdin)
+func extractCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("extract", stdout)
+	typeName := flagSet.String("type", "", "only extract IDs of this type (MALO, NELO, MELO, TRID or SRID)")
+	onlyValid := flagSet.Bool("valid", false, "only print valid IDs")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	var idType *IdType
+	if *typeName != "" {
+		requestedType, err := getIdType(*typeName)
+		if err != nil {
+			return err
+		}
+		idType = &requestedType
+	}
+	files, err := openInputFiles(flagSet.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	result := []ExtractedId{}
+	for index, file := range files {
+		text, readErr := io.ReadAll(file)
+		_ = file.Close()
+		if readErr != nil {
+			return readErr
+		}
+		extractedIds := ExtractIds(string(text), idType)
+		if *onlyValid {
+			extractedIds = filterValidIds(extractedIds)
+		}
+		for i := range extractedIds {
+			if index < flagSet.NArg() {
+				extractedIds[i].File = flagSet.Arg(index)
+			}
+		}
+		result = append(result, extractedIds...)
+	}
+	return writeJson(stdout, result)
+}
@@ -0,0 +1,88 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"strings"
+)
+
+// utilmdExample is a shortened UTILMD message
+const utilmdExample = "UNA:+.? '\n" +
+	"UNB+UNOC:3+9900000000003:500+9900000000010:500+240101:1200+ABC4711'\n" +
+	"UNH+1+UTILMD:D:11A:UN:S1.1'\n" +
+	"IDE+24+vorgang1'\n" +
+	"LOC+172+41373559241'\n" +
+	"LOC+Z16+41373559240'\n" +
+	"LOC+172+DE0010696664610000000000000012345'\n" +
+	"FTX+ACB+++Kunde sagt ?'E1137355921?' sei seine NeLo'\n" +
+	"UNT+7+1'\n" +
+	"UNZ+1+ABC4711'\n"
+
+func (s *Suite) Test_Extract_Ids_From_Free_Text() {
+	text := "Hallo,\nbitte prüft die MaLo 41373559241 und die Ressourcen D0000000001 sowie C1234567890.\nTelefon: 0123456789012"
+	extractedIds := idgenerator.ExtractIds(text, nil)
+	then.AssertThat(s.T(), len(extractedIds), is.EqualTo(3))
+	then.AssertThat(s.T(), extractedIds[0].Id, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), extractedIds[0].Type, is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), extractedIds[0].Valid, is.True())
+	then.AssertThat(s.T(), extractedIds[0].Line, is.EqualTo(2))
+	then.AssertThat(s.T(), extractedIds[0].Column, is.EqualTo(22)) // "ü" is one character but two bytes
+	then.AssertThat(s.T(), text[extractedIds[0].Offset:extractedIds[0].Offset+11], is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), extractedIds[1].Type, is.EqualTo("TR"))
+	then.AssertThat(s.T(), extractedIds[2].Type, is.EqualTo("SR"))
+	then.AssertThat(s.T(), extractedIds[2].Segment, is.EqualTo(""))
+}
+
+func (s *Suite) Test_Extract_Ids_From_Edifact() {
+	extractedIds := idgenerator.ExtractIds(utilmdExample, nil)
+	then.AssertThat(s.T(), len(extractedIds), is.EqualTo(4)) // the GLNs in the UNB segment are 13 digits long and no MaLos
+	then.AssertThat(s.T(), extractedIds[0].Segment, is.EqualTo("LOC+172"))
+	then.AssertThat(s.T(), extractedIds[1].Segment, is.EqualTo("LOC+Z16"))
+	then.AssertThat(s.T(), extractedIds[1].Valid, is.False())
+	then.AssertThat(s.T(), extractedIds[1].CorrectedId, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), extractedIds[2].Type, is.EqualTo("MeLo"))
+	then.AssertThat(s.T(), extractedIds[3].Id, is.EqualTo("E1137355921"))
+	then.AssertThat(s.T(), extractedIds[3].Segment, is.EqualTo("FTX+ACB")) // the escaped apostrophe does not terminate the segment
+
+	malos := idgenerator.ExtractIds(utilmdExample, &idgenerator.MaLoIdType)
+	then.AssertThat(s.T(), len(malos), is.EqualTo(2))
+}
+
+func (s *Suite) Test_Extract_Ids_From_Large_Single_Line() {
+	// EDIFACT messages often have no line breaks at all; the position of each ID must not be searched from the start of the text again
+	const header = "UNA:+.? 'UNB+UNOC:3+9900000000003:500+9900000000010:500+240101:1200+ABC4711'"
+	const segment = "LOC+172+41373559241'"
+	const numberOfSegments = 100_000
+	text := header + strings.Repeat(segment, numberOfSegments)
+	extractedIds := idgenerator.ExtractIds(text, nil)
+	then.AssertThat(s.T(), len(extractedIds), is.EqualTo(numberOfSegments))
+	last := extractedIds[numberOfSegments-1]
+	then.AssertThat(s.T(), last.Line, is.EqualTo(1))
+	then.AssertThat(s.T(), last.Offset, is.EqualTo(len(header)+(numberOfSegments-1)*len(segment)+len("LOC+172+")))
+	then.AssertThat(s.T(), last.Column, is.EqualTo(last.Offset+1))
+	then.AssertThat(s.T(), last.Segment, is.EqualTo("LOC+172"))
+}
+
+func (s *Suite) Test_Extraction_Endpoint() {
+	response := performRequest(idgenerator.NewRouter(), "POST", "/extract?valid=true", strings.NewReader(utilmdExample))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var extractedIds []idgenerator.ExtractedId
+	err := json.NewDecoder(response.Body).Decode(&extractedIds)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(extractedIds), is.EqualTo(3))
+}
+
+func (s *Suite) Test_Extract_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"extract", "-type", "nelo"}, strings.NewReader(utilmdExample), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	var extractedIds []idgenerator.ExtractedId
+	err := json.Unmarshal(stdout.Bytes(), &extractedIds)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(extractedIds), is.EqualTo(1))
+	then.AssertThat(s.T(), extractedIds[0].Line, is.EqualTo(8))
+}
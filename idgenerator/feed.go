@@ -0,0 +1,237 @@
+package idgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minFeedInterval is the shortest interval between two pushed IDs (i.e. at most 100 IDs per second and connection)
+const minFeedInterval = 10 * time.Millisecond
+
+// maxFeedRequestCount is the maximum number of IDs that a WebSocket client can request with a single message
+const maxFeedRequestCount = 100
+
+// parseFeedInterval parses the interval between two pushed IDs, either as duration (e.g. "500ms") or as rate per second (e.g. "rate=20" is the same as "interval=50ms").
+// An empty interval and rate result in the given default.
+func parseFeedInterval(interval string, rate string, defaultInterval time.Duration) (time.Duration, error) {
+	result := defaultInterval
+	switch {
+	case interval != "" && rate != "":
+		return 0, fmt.Errorf("only one of 'interval' and 'rate' may be set")
+	case interval != "":
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval '%s': %w", interval, err)
+		}
+		result = parsed
+	case rate != "":
+		perSecond, err := strconv.ParseFloat(rate, 64)
+		if err != nil || perSecond <= 0 {
+			return 0, fmt.Errorf("the rate has to be a positive number of IDs per second but was '%s'", rate)
+		}
+		result = time.Duration(float64(time.Second) / perSecond)
+	}
+	if result != 0 && result < minFeedInterval {
+		return 0, fmt.Errorf("the interval between two IDs has to be at least %s (at most %d IDs per second)", minFeedInterval, time.Second/minFeedInterval)
+	}
+	return result, nil
+}
+
+// eventsHandler pushes freshly generated IDs (the same objects as /json) as Server-Sent Events named "id" to the client, by default one per second.
+// Use ?type= to choose the type (default: the configured ID_TYPE_TO_GENERATE), ?interval= (e.g. 200ms) or ?rate= (IDs per second) to choose the rate and ?count= to limit the number of IDs;
+// without a count, IDs are pushed until the client disconnects. With a reservation store, every ID is recorded, so a count (of at most maxRecordedStreamCount) is required, as for /stream.
+func eventsHandler(c *gin.Context) {
+	idType, err := getIdTypeOrConfiguredFor(c, c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, err := parseFeedInterval(c.Query("interval"), c.Query("rate"), time.Second)
+	if err == nil && interval == 0 {
+		err = fmt.Errorf("the interval has to be positive")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	count := int64(0)
+	if countParameter := c.Query("count"); countParameter != "" {
+		if count, err = strconv.ParseInt(countParameter, 10, 64); err != nil || count < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the count has to be a non-negative integer but was '%s'", countParameter)})
+			return
+		}
+	}
+	store, err := getReservationStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if store != nil && (count == 0 || count > maxRecordedStreamCount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a count between 1 and %d is required because every pushed ID is recorded in the reservation store", maxRecordedStreamCount)})
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // otherwise reverse proxies like nginx buffer the events
+	generator := idType.Generator()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	done := c.Request.Context().Done()
+	for sent := int64(1); ; sent++ {
+		rawId, generationErr := generateUnusedIdDictionary(generator)
+		if generationErr != nil {
+			c.SSEvent("error", gin.H{"error": generationErr.Error()})
+			return
+		}
+		c.SSEvent("id", rawId)
+		c.Writer.Flush()
+		if count > 0 && sent >= count {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			log.Printf("Stopped pushing events after %d IDs because the client disconnected", sent)
+			return
+		}
+	}
+}
+
+// A FeedRequest is a message that a WebSocket client sends to request IDs on demand. Both fields are optional; an empty message requests a single ID of the connection's type.
+type FeedRequest struct {
+	Type  string `json:"type,omitempty"`  // Type overrides the type that was chosen when connecting
+	Count int    `json:"count,omitempty"` // Count is the number of IDs; defaults to 1
+}
+
+// websocketHandler upgrades the connection to a WebSocket over which freshly generated IDs (the same objects as /json) are sent as JSON text messages.
+// IDs are pushed periodically if ?interval= or ?rate= is given (see eventsHandler); in any case, the client can request IDs on demand by sending a FeedRequest.
+// With a reservation store, every ID is recorded, so the connection is closed after maxRecordedStreamCount IDs.
+func websocketHandler(c *gin.Context) {
+	idType, err := getIdTypeOrConfiguredFor(c, c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, err := parseFeedInterval(c.Query("interval"), c.Query("rate"), 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	store, err := getReservationStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	limit := 0 // no limit
+	if store != nil {
+		limit = maxRecordedStreamCount
+	}
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) { serveIdFeed(conn, idType, interval, limit) },
+		// the IDs are public test data, so connections from any origin (and from non-browser clients that send no origin at all) are accepted
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveIdFeed sends the IDs over the connection until the client disconnects or, if limit is positive, until limit IDs were sent.
+// Only this function writes to the connection; messages of the client are read in a separate goroutine.
+func serveIdFeed(conn *websocket.Conn, idType IdType, interval time.Duration, limit int) {
+	defer func() { _ = conn.Close() }()
+	messages := make(chan string)
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		defer close(messages)
+		for {
+			var message string
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+			select {
+			case messages <- message:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	sent := 0
+	for {
+		requestedType, count := idType, 1
+		select {
+		case <-tick:
+		case message, ok := <-messages:
+			if !ok {
+				log.Printf("Closed the WebSocket feed after %d IDs", sent)
+				return
+			}
+			var requestErr error
+			if requestedType, count, requestErr = parseFeedRequest(message, idType); requestErr != nil {
+				if err := websocket.JSON.Send(conn, gin.H{"error": requestErr.Error()}); err != nil {
+					return
+				}
+				continue
+			}
+		}
+		if limit > 0 && sent+count > limit {
+			_ = websocket.JSON.Send(conn, gin.H{"error": fmt.Sprintf("a connection can receive at most %d IDs because every ID is recorded in the reservation store", limit)})
+			log.Printf("Closed the WebSocket feed after %d IDs because it reached the limit", sent)
+			return
+		}
+		if err := sendFeedIds(conn, requestedType, count); err != nil {
+			return
+		}
+		sent += count
+	}
+}
+
+// parseFeedRequest parses a FeedRequest message of a WebSocket client; missing fields default to one ID of the given type
+func parseFeedRequest(message string, defaultType IdType) (IdType, int, error) {
+	var request FeedRequest
+	if strings.TrimSpace(message) != "" {
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return IdType{}, 0, fmt.Errorf("invalid request '%s': %w", message, err)
+		}
+	}
+	if request.Count == 0 {
+		request.Count = 1
+	}
+	if request.Count < 0 || request.Count > maxFeedRequestCount {
+		return IdType{}, 0, fmt.Errorf("the 'count' has to be between 1 and %d", maxFeedRequestCount)
+	}
+	if request.Type == "" {
+		return defaultType, request.Count, nil
+	}
+	idType, err := getIdType(request.Type)
+	if err != nil {
+		return IdType{}, 0, err
+	}
+	return idType, request.Count, nil
+}
+
+// sendFeedIds sends count freshly generated IDs of the type, one message each
+func sendFeedIds(conn *websocket.Conn, idType IdType, count int) error {
+	generator := idType.Generator()
+	for i := 0; i < count; i++ {
+		rawId, err := generateUnusedIdDictionary(generator)
+		if err != nil {
+			return websocket.JSON.Send(conn, gin.H{"error": err.Error()})
+		}
+		if err = websocket.JSON.Send(conn, rawId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
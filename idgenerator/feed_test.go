@@ -0,0 +1,92 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (s *Suite) Test_Events_With_Count() {
+	response := performGetRequest(idgenerator.NewRouter(), "/events?type=NELO&interval=10ms&count=3")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Header().Get("Content-Type"), is.EqualTo("text/event-stream;charset=utf-8"))
+	var ids []string
+	for _, line := range strings.Split(response.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data:"); ok {
+			var jsonResponse JsonResponse
+			then.AssertThat(s.T(), json.Unmarshal([]byte(data), &jsonResponse), is.Nil())
+			then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(jsonResponse.Id), is.Nil())
+			ids = append(ids, jsonResponse.Id)
+		}
+	}
+	then.AssertThat(s.T(), len(ids), is.EqualTo(3))
+	then.AssertThat(s.T(), strings.Count(response.Body.String(), "event:id"), is.EqualTo(3))
+}
+
+func (s *Suite) Test_Events_Rejects_Too_High_Rate() {
+	for _, query := range []string{"rate=1000", "interval=1ms", "interval=0s", "rate=abc", "rate=1&interval=1s"} {
+		response := performGetRequest(idgenerator.NewRouter(), "/events?type=MALO&"+query)
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	}
+}
+
+// dialFeed connects to the WebSocket feed of a new test server
+func (s *Suite) dialFeed(query string) (*websocket.Conn, func()) {
+	server := httptest.NewServer(idgenerator.NewRouter())
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1)+"/ws?"+query, "", server.URL)
+	then.AssertThat(s.T(), err, is.Nil())
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	return conn, func() {
+		_ = conn.Close()
+		server.Close()
+	}
+}
+
+func (s *Suite) Test_WebSocket_On_Demand() {
+	conn, closeFeed := s.dialFeed("type=MALO")
+	defer closeFeed()
+	then.AssertThat(s.T(), websocket.Message.Send(conn, ""), is.Nil())
+	var jsonResponse JsonResponse
+	then.AssertThat(s.T(), websocket.JSON.Receive(conn, &jsonResponse), is.Nil())
+	then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(jsonResponse.Id), is.Nil())
+
+	then.AssertThat(s.T(), websocket.Message.Send(conn, `{"type": "SRID", "count": 3}`), is.Nil())
+	for i := 0; i < 3; i++ {
+		then.AssertThat(s.T(), websocket.JSON.Receive(conn, &jsonResponse), is.Nil())
+		then.AssertThat(s.T(), idgenerator.SRIdType.Validate(jsonResponse.Id), is.Nil())
+	}
+
+	then.AssertThat(s.T(), websocket.Message.Send(conn, `{"count": 100000}`), is.Nil())
+	var errorResponse map[string]string
+	then.AssertThat(s.T(), websocket.JSON.Receive(conn, &errorResponse), is.Nil())
+	then.AssertThat(s.T(), errorResponse["error"], is.StringContaining("count"))
+}
+
+func (s *Suite) Test_WebSocket_Pushes_At_Rate() {
+	conn, closeFeed := s.dialFeed("type=TRID&rate=50")
+	defer closeFeed()
+	for i := 0; i < 5; i++ {
+		var jsonResponse JsonResponse
+		then.AssertThat(s.T(), websocket.JSON.Receive(conn, &jsonResponse), is.Nil())
+		then.AssertThat(s.T(), idgenerator.TRIdType.Validate(jsonResponse.Id), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Events_Require_Count_With_Reservation_Store() {
+	s.T().Setenv("RESERVATION_STORE_PATH", filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/events?type=MALO&interval=10ms")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performGetRequest(router, "/events?type=MALO&interval=10ms&count=1000001")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performGetRequest(router, "/events?type=MALO&interval=10ms&count=2")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Count(response.Body.String(), "event:id"), is.EqualTo(2))
+}
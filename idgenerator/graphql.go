@@ -0,0 +1,284 @@
+package idgenerator
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// graphqlSchema is the schema of the /graphql endpoint
+//
+//go:embed static/schema.graphql
+var graphqlSchema string
+
+// maxGraphqlCount is the maximum number of IDs of a single field of a GraphQL query
+const maxGraphqlCount = 100
+
+// maxGraphqlIdsPerQuery is the maximum number of IDs all fields of a GraphQL query may generate together (e.g. with many aliases of the same field)
+const maxGraphqlIdsPerQuery = 1000
+
+// parsedGraphqlSchema is the graphqlSchema bound to the graphqlResolver.
+// The depth is limited to what the introspection queries of GraphQL tools need; the query length mainly limits the number of aliases.
+var parsedGraphqlSchema = graphql.MustParseSchema(graphqlSchema, &graphqlResolver{}, graphql.MaxDepth(15), graphql.MaxQueryLength(10_000))
+
+// graphqlIdBudgetKey is the context key of the number of IDs the current query may still generate
+type graphqlIdBudgetKey struct{}
+
+// withGraphqlIdBudget returns a context that allows generating maxGraphqlIdsPerQuery IDs
+func withGraphqlIdBudget(ctx context.Context) context.Context {
+	budget := &atomic.Int64{}
+	budget.Store(maxGraphqlIdsPerQuery)
+	return context.WithValue(ctx, graphqlIdBudgetKey{}, budget)
+}
+
+// spendGraphqlIdBudget subtracts count from the ID budget of the query or returns an error if the budget is exhausted
+func spendGraphqlIdBudget(ctx context.Context, count int32) error {
+	budget, ok := ctx.Value(graphqlIdBudgetKey{}).(*atomic.Int64)
+	if !ok {
+		return fmt.Errorf("the query has no ID budget")
+	}
+	if budget.Add(-int64(count)) < 0 {
+		return fmt.Errorf("a query must not generate more than %d IDs in total", maxGraphqlIdsPerQuery)
+	}
+	return nil
+}
+
+// graphqlResolver resolves the queries of the graphqlSchema
+type graphqlResolver struct{}
+
+// countArgs are the arguments of the fields that return a list of new IDs
+type countArgs struct {
+	Count int32
+}
+
+// generatedIdResolver resolves the fields of all generated ID types from the dictionary of an IdGenerator. Each type only uses the fields that are defined for it in the schema.
+type generatedIdResolver struct {
+	rawId map[string]string
+}
+
+func (r generatedIdResolver) Id() graphql.ID {
+	return graphql.ID(r.rawId["id"])
+}
+
+func (r generatedIdResolver) IdWithoutChecksum() string {
+	return r.rawId["id"][:len(r.rawId["id"])-len(r.rawId["checksum"])]
+}
+
+func (r generatedIdResolver) Checksum() string {
+	return r.rawId["checksum"]
+}
+
+func (r generatedIdResolver) Issuer() string {
+	return r.rawId["issuer"]
+}
+
+func (r generatedIdResolver) Landesziffern() string {
+	return r.rawId["landesziffern"]
+}
+
+func (r generatedIdResolver) Netzbetreibernummer() string {
+	return r.rawId["netzbetreibernummer"]
+}
+
+func (r generatedIdResolver) Postleitzahl() string {
+	return r.rawId["postleitzahl"]
+}
+
+func (r generatedIdResolver) LaufendeNummer() string {
+	return r.rawId["laufendeNummer"]
+}
+
+// generateGraphqlIds generates count unused IDs of the type (see generateUnusedIdDictionary) and subtracts them from the ID budget of the query
+func generateGraphqlIds(ctx context.Context, idType IdType, count int32) ([]generatedIdResolver, error) {
+	if count < 0 || count > maxGraphqlCount {
+		return nil, fmt.Errorf("the count has to be between 0 and %d but was %d", maxGraphqlCount, count)
+	}
+	if err := spendGraphqlIdBudget(ctx, count); err != nil {
+		return nil, err
+	}
+	generator := idType.Generator()
+	result := make([]generatedIdResolver, 0, count)
+	for i := int32(0); i < count; i++ {
+		rawId, err := generateUnusedIdDictionary(generator)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, generatedIdResolver{rawId: rawId})
+	}
+	return result, nil
+}
+
+// generateGraphqlId generates a single unused ID of the type
+func generateGraphqlId(ctx context.Context, idType IdType) (generatedIdResolver, error) {
+	ids, err := generateGraphqlIds(ctx, idType, 1)
+	if err != nil {
+		return generatedIdResolver{}, err
+	}
+	return ids[0], nil
+}
+
+func (r *graphqlResolver) Malo(ctx context.Context) (generatedIdResolver, error) {
+	return generateGraphqlId(ctx, MaLoIdType)
+}
+
+func (r *graphqlResolver) Malos(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, MaLoIdType, args.Count)
+}
+
+func (r *graphqlResolver) Nelo(ctx context.Context) (generatedIdResolver, error) {
+	return generateGraphqlId(ctx, NeLoIdType)
+}
+
+func (r *graphqlResolver) Nelos(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, NeLoIdType, args.Count)
+}
+
+func (r *graphqlResolver) Melo(ctx context.Context) (generatedIdResolver, error) {
+	return generateGraphqlId(ctx, MeLoIdType)
+}
+
+func (r *graphqlResolver) Melos(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, MeLoIdType, args.Count)
+}
+
+func (r *graphqlResolver) Trid(ctx context.Context) (generatedIdResolver, error) {
+	return generateGraphqlId(ctx, TRIdType)
+}
+
+func (r *graphqlResolver) Trids(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, TRIdType, args.Count)
+}
+
+func (r *graphqlResolver) Srid(ctx context.Context) (generatedIdResolver, error) {
+	return generateGraphqlId(ctx, SRIdType)
+}
+
+func (r *graphqlResolver) Srids(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, SRIdType, args.Count)
+}
+
+// validationResolver resolves a ValidationResult
+type validationResolver struct {
+	result ValidationResult
+}
+
+func (r validationResolver) Value() string {
+	return r.result.Value
+}
+
+// Type returns the name of the IdType (which is the value of the GraphQL enum)
+func (r validationResolver) Type() *string {
+	for _, idType := range supportedIdTypes {
+		if idType.Label == r.result.Type {
+			return &idType.Name
+		}
+	}
+	return nil
+}
+
+func (r validationResolver) Valid() bool {
+	return r.result.Valid
+}
+
+// optionalString returns nil for empty strings, so that they're null in GraphQL responses
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (r validationResolver) Reason() *string {
+	return optionalString(r.result.Reason)
+}
+
+func (r validationResolver) CorrectedChecksum() *string {
+	return optionalString(r.result.CorrectedChecksum)
+}
+
+func (r validationResolver) CorrectedId() *string {
+	return optionalString(r.result.CorrectedId)
+}
+
+func (r *graphqlResolver) Validate(args struct {
+	Id   string
+	Type *string
+}) (validationResolver, error) {
+	var idType *IdType
+	if args.Type != nil {
+		requestedType, err := getIdType(*args.Type)
+		if err != nil {
+			return validationResolver{}, err
+		}
+		idType = &requestedType
+	}
+	return validationResolver{result: ValidateValue(idType, args.Id)}, nil
+}
+
+// scenarioResolver resolves a Scenario. The IDs are only generated if they're requested; the Marktlokation is generated once per scenario.
+type scenarioResolver struct {
+	once          sync.Once
+	marktlokation generatedIdResolver
+	err           error
+}
+
+func (r *graphqlResolver) Scenario() *scenarioResolver {
+	return &scenarioResolver{}
+}
+
+func (r *scenarioResolver) Marktlokation(ctx context.Context) (generatedIdResolver, error) {
+	r.once.Do(func() { r.marktlokation, r.err = generateGraphqlId(ctx, MaLoIdType) })
+	return r.marktlokation, r.err
+}
+
+func (r *scenarioResolver) Messlokationen(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, MeLoIdType, args.Count)
+}
+
+func (r *scenarioResolver) Netzlokationen(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, NeLoIdType, args.Count)
+}
+
+func (r *scenarioResolver) TechnischeRessourcen(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, TRIdType, args.Count)
+}
+
+func (r *scenarioResolver) SteuerbareRessourcen(ctx context.Context, args countArgs) ([]generatedIdResolver, error) {
+	return generateGraphqlIds(ctx, SRIdType, args.Count)
+}
+
+// graphqlRequest is the body of a POST request to /graphql (see https://graphql.org/learn/serving-over-http/)
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphqlHandler executes the GraphQL query from the JSON body (POST) or the query parameters (GET). Without a query, a GET request returns the schema.
+func graphqlHandler(c *gin.Context) {
+	var request graphqlRequest
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if request.Query == "" {
+			c.String(http.StatusOK, graphqlSchema)
+			return
+		}
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid variables: %s", err.Error())})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, parsedGraphqlSchema.Exec(withGraphqlIdBudget(c.Request.Context()), request.Query, request.OperationName, request.Variables))
+}
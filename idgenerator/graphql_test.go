@@ -0,0 +1,113 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (s *Suite) performGraphqlQuery(query string, variables map[string]any) graphqlResponse {
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	response := performRequest(idgenerator.NewRouter(), "POST", "/graphql", strings.NewReader(string(body)))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var result graphqlResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &result), is.Nil())
+	return result
+}
+
+func (s *Suite) Test_Graphql_Returns_Only_The_Requested_Fields() {
+	result := s.performGraphqlQuery(`{ malo { id issuer } melos(count: 2) { id postleitzahl } }`, nil)
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(0))
+	var malo map[string]string
+	then.AssertThat(s.T(), json.Unmarshal(result.Data["malo"], &malo), is.Nil())
+	then.AssertThat(s.T(), len(malo), is.EqualTo(2))
+	then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(malo["id"]), is.Nil())
+	then.AssertThat(s.T(), malo["issuer"] == "BDEW" || malo["issuer"] == "DVGW", is.True())
+	var melos []map[string]string
+	then.AssertThat(s.T(), json.Unmarshal(result.Data["melos"], &melos), is.Nil())
+	then.AssertThat(s.T(), len(melos), is.EqualTo(2))
+	for _, melo := range melos {
+		then.AssertThat(s.T(), idgenerator.MeLoIdType.Validate(melo["id"]), is.Nil())
+		then.AssertThat(s.T(), melo["id"][8:13], is.EqualTo(melo["postleitzahl"]))
+	}
+}
+
+func (s *Suite) Test_Graphql_Checksum_Fields() {
+	result := s.performGraphqlQuery(`{ nelo { id idWithoutChecksum checksum } }`, nil)
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(0))
+	var nelo map[string]string
+	then.AssertThat(s.T(), json.Unmarshal(result.Data["nelo"], &nelo), is.Nil())
+	then.AssertThat(s.T(), nelo["idWithoutChecksum"]+nelo["checksum"], is.EqualTo(nelo["id"]))
+}
+
+func (s *Suite) Test_Graphql_Validation() {
+	result := s.performGraphqlQuery(`query($id: String!) { validate(id: $id) { type valid reason correctedId } }`, map[string]any{"id": "41373559240"})
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(0))
+	var validation struct {
+		Type        string `json:"type"`
+		Valid       bool   `json:"valid"`
+		Reason      string `json:"reason"`
+		CorrectedId string `json:"correctedId"`
+	}
+	then.AssertThat(s.T(), json.Unmarshal(result.Data["validate"], &validation), is.Nil())
+	then.AssertThat(s.T(), validation.Type, is.EqualTo("MALO"))
+	then.AssertThat(s.T(), validation.Valid, is.False())
+	then.AssertThat(s.T(), validation.CorrectedId, is.EqualTo("41373559241"))
+}
+
+func (s *Suite) Test_Graphql_Scenario() {
+	result := s.performGraphqlQuery(`{ scenario { marktlokation { id } messlokationen(count: 3) { id } technischeRessourcen { id } } }`, nil)
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(0))
+	var scenario struct {
+		Marktlokation        struct{ Id string }   `json:"marktlokation"`
+		Messlokationen       []struct{ Id string } `json:"messlokationen"`
+		TechnischeRessourcen []struct{ Id string } `json:"technischeRessourcen"`
+	}
+	then.AssertThat(s.T(), json.Unmarshal(result.Data["scenario"], &scenario), is.Nil())
+	then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(scenario.Marktlokation.Id), is.Nil())
+	then.AssertThat(s.T(), len(scenario.Messlokationen), is.EqualTo(3))
+	then.AssertThat(s.T(), len(scenario.TechnischeRessourcen), is.EqualTo(1))
+	then.AssertThat(s.T(), idgenerator.TRIdType.Validate(scenario.TechnischeRessourcen[0].Id), is.Nil())
+}
+
+func (s *Suite) Test_Graphql_Rejects_Too_Many_Ids() {
+	result := s.performGraphqlQuery(`{ malos(count: 100000) { id } }`, nil)
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(1))
+}
+
+func (s *Suite) Test_Graphql_Limits_The_Ids_Per_Query() {
+	var query strings.Builder
+	query.WriteString("{")
+	for i := 0; i < 11; i++ {
+		query.WriteString(fmt.Sprintf(" a%d: malos(count: 100) { id }", i))
+	}
+	query.WriteString(" }")
+	result := s.performGraphqlQuery(query.String(), nil)
+	then.AssertThat(s.T(), len(result.Errors), is.GreaterThan(0))
+	then.AssertThat(s.T(), result.Errors[0].Message, is.StringContaining("1000 IDs"))
+
+	result = s.performGraphqlQuery("{ malo { id } "+strings.Repeat(" ", 10_000)+"}", nil)
+	then.AssertThat(s.T(), len(result.Errors), is.EqualTo(1))
+}
+
+func (s *Suite) Test_Graphql_Get() {
+	response := performGetRequest(idgenerator.NewRouter(), "/graphql?query="+url.QueryEscape(`{ srid { id } }`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`"srid":{"id":"`))
+
+	response = performGetRequest(idgenerator.NewRouter(), "/graphql")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining("type Query"))
+}
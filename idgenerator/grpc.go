@@ -0,0 +1,203 @@
+package idgenerator
+
+import (
+	"context"
+	"fmt"
+	idgeneratorv1 "github.com/hochfrequenz/malo-id-generator/proto/idgenerator/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// grpcPortVariable is the environment variable that contains the port of the gRPC server. The gRPC server is only started if it's set.
+const grpcPortVariable = "GRPC_PORT"
+
+// maxGrpcBatchSize is the maximum number of IDs of a single GenerateBatch call
+const maxGrpcBatchSize = maxJobSize
+
+// grpcIdTypePrefix is the prefix of the names of the IdType enum values, followed by the IdType.Name
+const grpcIdTypePrefix = "ID_TYPE_"
+
+// idGeneratorServer implements the IdGeneratorService with the same generators as the HTTP API
+type idGeneratorServer struct {
+	idgeneratorv1.UnimplementedIdGeneratorServiceServer
+}
+
+// NewGrpcServer returns a gRPC server with the registered IdGeneratorService (see proto/idgenerator/v1/id_generator.proto)
+func NewGrpcServer() *grpc.Server {
+	server := grpc.NewServer()
+	idgeneratorv1.RegisterIdGeneratorServiceServer(server, &idGeneratorServer{})
+	return server
+}
+
+// startGrpcServer starts the gRPC server in the background if GRPC_PORT is set, so that it runs alongside the gin router
+func startGrpcServer() error {
+	port, ok := os.LookupEnv(grpcPortVariable)
+	if !ok || port == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("could not listen on the gRPC port '%s': %w", port, err)
+	}
+	go func() {
+		log.Printf("Serving gRPC on %s", listener.Addr())
+		if serveErr := NewGrpcServer().Serve(listener); serveErr != nil {
+			log.Panic(serveErr)
+		}
+	}()
+	return nil
+}
+
+// toGrpcIdType returns the enum value of the IdType
+func toGrpcIdType(idType IdType) idgeneratorv1.IdType {
+	return idgeneratorv1.IdType(idgeneratorv1.IdType_value[grpcIdTypePrefix+idType.Name])
+}
+
+// fromGrpcIdType returns the IdType of a (specified) enum value
+func fromGrpcIdType(grpcIdType idgeneratorv1.IdType) (IdType, error) {
+	name, ok := idgeneratorv1.IdType_name[int32(grpcIdType)]
+	if !ok || grpcIdType == idgeneratorv1.IdType_ID_TYPE_UNSPECIFIED {
+		return IdType{}, status.Errorf(codes.InvalidArgument, "unsupported ID type %d", grpcIdType)
+	}
+	idType, err := getIdType(strings.TrimPrefix(name, grpcIdTypePrefix))
+	if err != nil {
+		return IdType{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return idType, nil
+}
+
+// getGrpcGenerator returns the generator for the requested type or, if it's unspecified, for the configured ID_TYPE_TO_GENERATE
+func getGrpcGenerator(grpcIdType idgeneratorv1.IdType) (IdGenerator, error) {
+	if grpcIdType != idgeneratorv1.IdType_ID_TYPE_UNSPECIFIED {
+		idType, err := fromGrpcIdType(grpcIdType)
+		if err != nil {
+			return nil, err
+		}
+		return idType.Generator(), nil
+	}
+	generator, err := getIdGenerator()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return generator, nil
+}
+
+// getGrpcIdType returns the requested type or, if it's unspecified, the type detected from the id
+func getGrpcIdType(grpcIdType idgeneratorv1.IdType, id string) (IdType, error) {
+	if grpcIdType != idgeneratorv1.IdType_ID_TYPE_UNSPECIFIED {
+		return fromGrpcIdType(grpcIdType)
+	}
+	if idType, ok := detectIdType(id); ok {
+		return idType, nil
+	}
+	return IdType{}, status.Errorf(codes.InvalidArgument, "could not detect the type of '%s'; please provide the type", id)
+}
+
+// generateGrpcResponse generates an unused ID (see generateUnusedIdDictionary)
+func generateGrpcResponse(generator IdGenerator) (*idgeneratorv1.GenerateResponse, error) {
+	rawId, err := generateUnusedIdDictionary(generator)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response := &idgeneratorv1.GenerateResponse{Id: rawId["id"], Checksum: rawId["checksum"], Details: rawId}
+	for _, idType := range supportedIdTypes {
+		if idType.Label == rawId["type"] {
+			response.Type = toGrpcIdType(idType)
+		}
+	}
+	return response, nil
+}
+
+// Generate returns a single freshly generated ID
+func (s *idGeneratorServer) Generate(_ context.Context, request *idgeneratorv1.GenerateRequest) (*idgeneratorv1.GenerateResponse, error) {
+	generator, err := getGrpcGenerator(request.GetType())
+	if err != nil {
+		return nil, err
+	}
+	return generateGrpcResponse(generator)
+}
+
+// GenerateBatch streams the requested number of freshly generated IDs. It stops early if the client cancels the call.
+func (s *idGeneratorServer) GenerateBatch(request *idgeneratorv1.GenerateBatchRequest, stream grpc.ServerStreamingServer[idgeneratorv1.GenerateResponse]) error {
+	if request.GetCount() < 1 || request.GetCount() > maxGrpcBatchSize {
+		return status.Errorf(codes.InvalidArgument, "the count has to be between 1 and %d but was %d", maxGrpcBatchSize, request.GetCount())
+	}
+	generator, err := getGrpcGenerator(request.GetType())
+	if err != nil {
+		return err
+	}
+	for generated := int64(0); generated < request.GetCount(); generated++ {
+		if contextErr := stream.Context().Err(); contextErr != nil {
+			return status.FromContextError(contextErr).Err()
+		}
+		response, generationErr := generateGrpcResponse(generator)
+		if generationErr != nil {
+			return generationErr
+		}
+		if err = stream.Send(response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the ID like /validate does
+func (s *idGeneratorServer) Validate(_ context.Context, request *idgeneratorv1.ValidateRequest) (*idgeneratorv1.ValidateResponse, error) {
+	var idType *IdType
+	if request.GetType() != idgeneratorv1.IdType_ID_TYPE_UNSPECIFIED {
+		requestedType, err := fromGrpcIdType(request.GetType())
+		if err != nil {
+			return nil, err
+		}
+		idType = &requestedType
+	}
+	result := ValidateValue(idType, request.GetId())
+	response := &idgeneratorv1.ValidateResponse{Valid: result.Valid, Reason: result.Reason, CorrectedChecksum: result.CorrectedChecksum, CorrectedId: result.CorrectedId}
+	for _, supportedType := range supportedIdTypes {
+		if supportedType.Label == result.Type {
+			response.Type = toGrpcIdType(supportedType)
+		}
+	}
+	return response, nil
+}
+
+// Explain returns the step by step calculation of the checksum, like /explain does
+func (s *idGeneratorServer) Explain(_ context.Context, request *idgeneratorv1.ExplainRequest) (*idgeneratorv1.ExplainResponse, error) {
+	id := strings.ToUpper(strings.TrimSpace(request.GetId()))
+	idType, err := getGrpcIdType(request.GetType(), id)
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := ExplainChecksum(idType, id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	response := &idgeneratorv1.ExplainResponse{
+		Type:              toGrpcIdType(idType),
+		Id:                explanation.Id,
+		IdWithoutChecksum: explanation.IdWithoutChecksum,
+		OddSum:            int32(explanation.OddSum),
+		EvenSum:           int32(explanation.EvenSum),
+		WeightedEvenSum:   int32(explanation.WeightedEvenSum),
+		Sum:               int32(explanation.Sum),
+		Remainder:         int32(explanation.Remainder),
+		NextMultipleOf_10: int32(explanation.NextMultipleOf10),
+		Checksum:          explanation.Checksum,
+		GivenChecksum:     explanation.GivenChecksum,
+	}
+	for _, step := range explanation.Steps {
+		response.Steps = append(response.Steps, &idgeneratorv1.ChecksumStep{
+			Position:  int32(step.Position),
+			Character: step.Character,
+			Value:     int32(step.Value),
+			Weight:    int32(step.Weight),
+			Product:   int32(step.Product),
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,97 @@
+package idgenerator_test
+
+import (
+	"context"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	idgeneratorv1 "github.com/hochfrequenz/malo-id-generator/proto/idgenerator/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+)
+
+// newGrpcClient starts the gRPC server on an in-memory listener and returns a client for it
+func (s *Suite) newGrpcClient() idgeneratorv1.IdGeneratorServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := idgenerator.NewGrpcServer()
+	go func() { _ = server.Serve(listener) }()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	then.AssertThat(s.T(), err, is.Nil())
+	s.T().Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+	return idgeneratorv1.NewIdGeneratorServiceClient(conn)
+}
+
+func (s *Suite) Test_Grpc_Generate() {
+	client := s.newGrpcClient()
+	response, err := client.Generate(context.Background(), &idgeneratorv1.GenerateRequest{Type: idgeneratorv1.IdType_ID_TYPE_NELO})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.GetType(), is.EqualTo(idgeneratorv1.IdType_ID_TYPE_NELO))
+	then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(response.GetId()), is.Nil())
+	then.AssertThat(s.T(), response.GetDetails()["neLoIdWithoutChecksum"]+response.GetChecksum(), is.EqualTo(response.GetId()))
+}
+
+func (s *Suite) Test_Grpc_Generate_Uses_Configured_Type() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MELO")
+	response, err := s.newGrpcClient().Generate(context.Background(), &idgeneratorv1.GenerateRequest{})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.GetType(), is.EqualTo(idgeneratorv1.IdType_ID_TYPE_MELO))
+	then.AssertThat(s.T(), idgenerator.MeLoIdType.Validate(response.GetId()), is.Nil())
+}
+
+func (s *Suite) Test_Grpc_Generate_Batch() {
+	stream, err := s.newGrpcClient().GenerateBatch(context.Background(), &idgeneratorv1.GenerateBatchRequest{Type: idgeneratorv1.IdType_ID_TYPE_SRID, Count: 25})
+	then.AssertThat(s.T(), err, is.Nil())
+	ids := map[string]bool{}
+	for {
+		response, receiveErr := stream.Recv()
+		if receiveErr == io.EOF {
+			break
+		}
+		then.AssertThat(s.T(), receiveErr, is.Nil())
+		then.AssertThat(s.T(), idgenerator.SRIdType.Validate(response.GetId()), is.Nil())
+		ids[response.GetId()] = true
+	}
+	then.AssertThat(s.T(), len(ids), is.EqualTo(25))
+}
+
+func (s *Suite) Test_Grpc_Generate_Batch_Rejects_Invalid_Count() {
+	stream, err := s.newGrpcClient().GenerateBatch(context.Background(), &idgeneratorv1.GenerateBatchRequest{Type: idgeneratorv1.IdType_ID_TYPE_MALO})
+	then.AssertThat(s.T(), err, is.Nil())
+	_, err = stream.Recv()
+	then.AssertThat(s.T(), status.Code(err), is.EqualTo(codes.InvalidArgument))
+}
+
+func (s *Suite) Test_Grpc_Validate() {
+	client := s.newGrpcClient()
+	response, err := client.Validate(context.Background(), &idgeneratorv1.ValidateRequest{Id: "41373559241"})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.GetValid(), is.True())
+	then.AssertThat(s.T(), response.GetType(), is.EqualTo(idgeneratorv1.IdType_ID_TYPE_MALO))
+
+	response, err = client.Validate(context.Background(), &idgeneratorv1.ValidateRequest{Id: "41373559240", Type: idgeneratorv1.IdType_ID_TYPE_MALO})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.GetValid(), is.False())
+	then.AssertThat(s.T(), response.GetCorrectedId(), is.EqualTo("41373559241"))
+}
+
+func (s *Suite) Test_Grpc_Explain() {
+	client := s.newGrpcClient()
+	response, err := client.Explain(context.Background(), &idgeneratorv1.ExplainRequest{Id: "4137355924", Type: idgeneratorv1.IdType_ID_TYPE_MALO})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.GetId(), is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), response.GetChecksum(), is.EqualTo("1"))
+	then.AssertThat(s.T(), len(response.GetSteps()), is.EqualTo(10))
+
+	_, err = client.Explain(context.Background(), &idgeneratorv1.ExplainRequest{Id: "DE0010696664610000000000000012345"})
+	then.AssertThat(s.T(), status.Code(err), is.EqualTo(codes.InvalidArgument))
+}
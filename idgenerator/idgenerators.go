@@ -1,4 +1,4 @@
-package main
+package idgenerator
 
 import (
 	"fmt"
@@ -9,6 +9,7 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -39,18 +40,143 @@ https://www.hochfrequenz.de/jobs/
 // allowedMaLoCharacters contains those characters that are used to create new malo ids
 var allowedMaLoCharacters = []rune("0123456789")
 
+// randomSource is the source of the random characters of all generated IDs. It's seeded with the current time unless Seed is called.
+var randomSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// randomSourceMutex guards randomSource, which is not safe for concurrent use
+var randomSourceMutex sync.Mutex
+
+// Seed re-seeds the generators, so that they return the same sequence of IDs for the same seed (as long as they are called in the same order and no IDs are rejected by the exclusion list or the reservation store).
+// This is meant for reproducible tests; don't use it in production.
+func Seed(seed int64) {
+	randomSourceMutex.Lock()
+	defer randomSourceMutex.Unlock()
+	randomSource = rand.New(rand.NewSource(seed))
+}
+
 // generateRandomString returns a random combination of the allowed characters with given length
 func generateRandomString(allowedCharacters []rune, length uint) string {
 	// source: https://stackoverflow.com/a/22892986/10009545
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomSourceMutex.Lock()
+	defer randomSourceMutex.Unlock()
 	b := make([]rune, length)
 	for i := range b {
-		b[i] = allowedCharacters[r.Intn(len(allowedCharacters))]
+		b[i] = allowedCharacters[randomSource.Intn(len(allowedCharacters))]
 	}
-	time.Sleep(1 * time.Nanosecond) // gives the seed time to be refreshed
 	return string(b)
 }
 
+// generateUnusedIdDictionary calls the generator until it returns an ID that is not on the exclusion list (if configured) and was not handed out before (if a reservation store is configured).
+func generateUnusedIdDictionary(generator IdGenerator) (map[string]string, error) {
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return nil, err
+	}
+	store, err := getReservationStore()
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		rawId, generationErr := generator.generateIdDictionary()
+		if generationErr != nil {
+			return nil, generationErr
+		}
+		if exclusions.rejects(rawId["type"], rawId["id"]) {
+			log.Printf("Rejected the %s '%s' because it's on the exclusion list", rawId["type"], rawId["id"])
+			continue
+		}
+		if store == nil {
+			return rawId, nil
+		}
+		idType, typeErr := getIdType(rawId["type"])
+		if typeErr != nil {
+			return nil, typeErr
+		}
+		isNew, issueErr := store.Issue(idType, rawId["id"])
+		if issueErr != nil {
+			return nil, issueErr
+		}
+		if isNew {
+			return rawId, nil
+		}
+	}
+	return nil, fmt.Errorf("could not generate an unused ID within %d attempts", maxGenerationAttempts)
+}
+
+// generateUnusedIdDictionaries returns count IDs of the generator like generateUnusedIdDictionary, but records them in the reservation store (if configured) with a single write
+// instead of one per ID. It fails if maxGenerationAttempts IDs in a row are rejected.
+func generateUnusedIdDictionaries(generator IdGenerator, count int) ([]map[string]string, error) {
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return nil, err
+	}
+	store, err := getReservationStore()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]string, 0, count)
+	chosen := map[string]bool{}
+	rejectedInARow := 0
+	reject := func() error {
+		if rejectedInARow++; rejectedInARow >= maxGenerationAttempts {
+			return fmt.Errorf("could not generate an unused ID within %d attempts", maxGenerationAttempts)
+		}
+		return nil
+	}
+	for len(result) < count {
+		candidates := make([]map[string]string, 0, count-len(result))
+		ids := make([]string, 0, count-len(result))
+		for len(result)+len(candidates) < count {
+			rawId, generationErr := generator.generateIdDictionary()
+			if generationErr != nil {
+				return nil, generationErr
+			}
+			if chosen[rawId["id"]] || exclusions.rejects(rawId["type"], rawId["id"]) {
+				if err = reject(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			chosen[rawId["id"]] = true
+			rejectedInARow = 0
+			candidates = append(candidates, rawId)
+			ids = append(ids, rawId["id"])
+		}
+		if store == nil {
+			result = append(result, candidates...)
+			continue
+		}
+		idType, typeErr := getIdType(candidates[0]["type"]) // all IDs of a generator have the same type
+		if typeErr != nil {
+			return nil, typeErr
+		}
+		isNew, issueErr := store.IssueAll(idType, ids)
+		if issueErr != nil {
+			return nil, issueErr
+		}
+		for i, candidate := range candidates {
+			if !isNew[i] {
+				if err = reject(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			rejectedInARow = 0
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+// maLoIssuer returns who issued the MaLo-ID (with or without checksum): MaLo-IDs starting with 1, 2 or 3 are issued by the DVGW, the others by the BDEW
+func maLoIssuer(maloId string) rollencodetyp.Rollencodetyp {
+	// see https://bdew-codes.de/Content/Files/MaLo/2017-04-28-BDEW-Anwendungshilfe-MaLo-ID_Version1.0_FINAL.PDF
+	if rune(maloId[0]) < '4' {
+		return rollencodetyp.DVGW
+	}
+	return rollencodetyp.BDEW
+}
+
 // MaLoIdGenerator is an IdGenerator that generates MaLo-IDs (Marktlokations-IDs)
 type MaLoIdGenerator struct{}
 
@@ -68,13 +194,7 @@ func (m MaLoIdGenerator) generateIdDictionary() (map[string]string, error) {
 			break
 		}
 	}
-	var issuer rollencodetyp.Rollencodetyp
-	// see https://bdew-codes.de/Content/Files/MaLo/2017-04-28-BDEW-Anwendungshilfe-MaLo-ID_Version1.0_FINAL.PDF
-	if rune(maloIdWithoutChecksum[0]) < '4' {
-		issuer = rollencodetyp.DVGW
-	} else {
-		issuer = rollencodetyp.BDEW
-	}
+	issuer := maLoIssuer(maloIdWithoutChecksum)
 	maloId := maloIdWithoutChecksum + maloCheckSum
 	result := make(map[string]string)
 	result["id"] = maloId
@@ -86,7 +206,7 @@ func (m MaLoIdGenerator) generateIdDictionary() (map[string]string, error) {
 	return result, nil
 }
 func (m MaLoIdGenerator) GenerateIdRaw(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,12 +216,12 @@ func (m MaLoIdGenerator) GenerateIdRaw(c *gin.Context) {
 
 // GenerateId of the MaLoIdGenerator returns a new random, 11 digit malo-id that has a valid check sum
 func (m MaLoIdGenerator) GenerateId(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.HTML(http.StatusOK, "static/templates/malo.tmpl.html", gin.H{
+	renderHtml(c, "static/templates/malo.tmpl.html", gin.H{
 		"maLoIdWithoutChecksum": rawId["maLoIdWithoutChecksum"],
 		"checksum":              rawId["checksum"],
 		"issuer":                rawId["issuer"],
@@ -134,19 +254,19 @@ func (m NeLoIdGenerator) generateIdDictionary() (map[string]string, error) {
 
 // GenerateId of the NeLoIdGenerator returns a new random, 11 digit nelo-id that has a valid check sum
 func (m NeLoIdGenerator) GenerateId(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.HTML(http.StatusOK, "static/templates/nelo.tmpl.html", gin.H{
+	renderHtml(c, "static/templates/nelo.tmpl.html", gin.H{
 		"neLoIdWithoutChecksum": rawId["neLoIdWithoutChecksum"],
 		"checksum":              rawId["checksum"],
 		"recruitingMessage":     template.HTML(recruitingMessage),
 	})
 }
 func (m NeLoIdGenerator) GenerateIdRaw(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -187,12 +307,12 @@ func (m MeLoIdGenerator) generateIdDictionary() (map[string]string, error) {
 
 // GenerateId of the MeLoIdGenerator returns a new random, 33 character melo-id; MeLo-IDs have no checksum
 func (m MeLoIdGenerator) GenerateId(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.HTML(http.StatusOK, "static/templates/melo.tmpl.html", gin.H{
+	renderHtml(c, "static/templates/melo.tmpl.html", gin.H{
 		"meloId":              rawId["id"],
 		"landesziffern":       rawId["landesziffern"],
 		"netzbetreibernummer": rawId["netzbetreibernummer"],
@@ -202,7 +322,7 @@ func (m MeLoIdGenerator) GenerateId(c *gin.Context) {
 	})
 }
 func (m MeLoIdGenerator) GenerateIdRaw(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -237,19 +357,19 @@ func (m TRIdGenerator) generateIdDictionary() (map[string]string, error) {
 
 // GenerateId of the TRIdGenerator returns a new random, 11 digit tr-id that has a valid check sum
 func (m TRIdGenerator) GenerateId(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.HTML(http.StatusOK, "static/templates/trid.tmpl.html", gin.H{
+	renderHtml(c, "static/templates/trid.tmpl.html", gin.H{
 		"trIdWithoutChecksum": rawId["trIdWithoutChecksum"],
 		"checksum":            rawId["checksum"],
 		"recruitingMessage":   template.HTML(recruitingMessage),
 	})
 }
 func (m TRIdGenerator) GenerateIdRaw(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -279,19 +399,19 @@ func (m SRIdGenerator) generateIdDictionary() (map[string]string, error) {
 
 // GenerateId of the SRIdGenerator returns a new random, 11 digit sr-id that has a valid check sum
 func (m SRIdGenerator) GenerateId(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.HTML(http.StatusOK, "static/templates/srid.tmpl.html", gin.H{
+	renderHtml(c, "static/templates/srid.tmpl.html", gin.H{
 		"srIdWithoutChecksum": rawId["srIdWithoutChecksum"],
 		"checksum":            rawId["checksum"],
 		"recruitingMessage":   template.HTML(recruitingMessage),
 	})
 }
 func (m SRIdGenerator) GenerateIdRaw(c *gin.Context) {
-	rawId, err := m.generateIdDictionary()
+	rawId, err := generateUnusedIdDictionary(m)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
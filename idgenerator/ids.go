@@ -0,0 +1,268 @@
+package idgenerator
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/hochfrequenz/go-bo4e/enum/rollencodetyp"
+	"strings"
+)
+
+// typedId is the common implementation of the typed IDs (MaLoID, NeLoID, MeLoID, TRID and SRID).
+// The zero value means "no ID": it's marshalled as empty string (or NULL in SQL), and empty strings and NULLs are unmarshalled/scanned to it.
+type typedId struct {
+	id string
+}
+
+// parseTypedId normalizes the value (trims whitespace and converts it to upper case) and checks that it is a valid ID of the type
+func parseTypedId(idType IdType, value string) (typedId, error) {
+	id := strings.ToUpper(strings.TrimSpace(value))
+	if err := idType.Validate(id); err != nil {
+		return typedId{}, err
+	}
+	return typedId{id: id}, nil
+}
+
+// String returns the ID
+func (t typedId) String() string {
+	return t.id
+}
+
+// IsZero returns true if this is the zero value, i.e. no ID
+func (t typedId) IsZero() bool {
+	return t.id == ""
+}
+
+// MarshalText returns the ID (implements encoding.TextMarshaler)
+func (t typedId) MarshalText() ([]byte, error) {
+	return []byte(t.id), nil
+}
+
+// MarshalJSON returns the ID as JSON string (implements json.Marshaler)
+func (t typedId) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.id)
+}
+
+// Value returns the ID as string or nil for the zero value (implements driver.Valuer)
+func (t typedId) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.id, nil
+}
+
+// unmarshalText parses the text as ID of the type; empty texts result in the zero value
+func (t *typedId) unmarshalText(idType IdType, text []byte) error {
+	if len(text) == 0 {
+		*t = typedId{}
+		return nil
+	}
+	parsed, err := parseTypedId(idType, string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// scan reads an ID of the type from a database column; NULL results in the zero value
+func (t *typedId) scan(idType IdType, src any) error {
+	switch value := src.(type) {
+	case nil:
+		*t = typedId{}
+		return nil
+	case string:
+		return t.unmarshalText(idType, []byte(value))
+	case []byte:
+		return t.unmarshalText(idType, value)
+	default:
+		return fmt.Errorf("cannot scan a %T into a %s-ID", src, idType.Label)
+	}
+}
+
+// checksum returns the last character of the ID (the check digit)
+func (t typedId) checksum() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.id[len(t.id)-1:]
+}
+
+// withoutChecksum returns the ID without its last character (the check digit)
+func (t typedId) withoutChecksum() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.id[:len(t.id)-1]
+}
+
+// A MaLoID is a valid Marktlokations-ID. Create it with ParseMaLoID.
+type MaLoID struct{ typedId }
+
+// ParseMaLoID returns the MaLoID or an error if the value is not a valid MaLo-ID (structure and checksum)
+func ParseMaLoID(value string) (MaLoID, error) {
+	id, err := parseTypedId(MaLoIdType, value)
+	return MaLoID{id}, err
+}
+
+// UnmarshalText parses a MaLo-ID (implements encoding.TextUnmarshaler)
+func (m *MaLoID) UnmarshalText(text []byte) error {
+	return m.unmarshalText(MaLoIdType, text)
+}
+
+// Scan reads a MaLo-ID from a database column (implements sql.Scanner)
+func (m *MaLoID) Scan(src any) error {
+	return m.scan(MaLoIdType, src)
+}
+
+// Checksum returns the check digit (the last digit)
+func (m MaLoID) Checksum() string {
+	return m.checksum()
+}
+
+// WithoutChecksum returns the first 10 digits
+func (m MaLoID) WithoutChecksum() string {
+	return m.withoutChecksum()
+}
+
+// Issuer returns who issued the MaLo-ID: the DVGW (first digit 1-3) or the BDEW (first digit 4-9); 0 for the zero value
+func (m MaLoID) Issuer() rollencodetyp.Rollencodetyp {
+	if m.IsZero() {
+		return 0
+	}
+	return maLoIssuer(m.id)
+}
+
+// A NeLoID is a valid Netzlokations-ID. Create it with ParseNeLoID.
+type NeLoID struct{ typedId }
+
+// ParseNeLoID returns the NeLoID or an error if the value is not a valid NeLo-ID (structure and checksum)
+func ParseNeLoID(value string) (NeLoID, error) {
+	id, err := parseTypedId(NeLoIdType, value)
+	return NeLoID{id}, err
+}
+
+// UnmarshalText parses a NeLo-ID (implements encoding.TextUnmarshaler)
+func (n *NeLoID) UnmarshalText(text []byte) error {
+	return n.unmarshalText(NeLoIdType, text)
+}
+
+// Scan reads a NeLo-ID from a database column (implements sql.Scanner)
+func (n *NeLoID) Scan(src any) error {
+	return n.scan(NeLoIdType, src)
+}
+
+// Checksum returns the check digit (the last digit)
+func (n NeLoID) Checksum() string {
+	return n.checksum()
+}
+
+// WithoutChecksum returns the first 10 characters
+func (n NeLoID) WithoutChecksum() string {
+	return n.withoutChecksum()
+}
+
+// A MeLoID is a valid Messlokations-ID. Create it with ParseMeLoID. MeLo-IDs have no checksum.
+type MeLoID struct{ typedId }
+
+// ParseMeLoID returns the MeLoID or an error if the value is not a valid MeLo-ID
+func ParseMeLoID(value string) (MeLoID, error) {
+	id, err := parseTypedId(MeLoIdType, value)
+	return MeLoID{id}, err
+}
+
+// UnmarshalText parses a MeLo-ID (implements encoding.TextUnmarshaler)
+func (m *MeLoID) UnmarshalText(text []byte) error {
+	return m.unmarshalText(MeLoIdType, text)
+}
+
+// Scan reads a MeLo-ID from a database column (implements sql.Scanner)
+func (m *MeLoID) Scan(src any) error {
+	return m.scan(MeLoIdType, src)
+}
+
+// meLoComponent returns the characters [start:end] of the MeLo-ID (see the MeLoIdGenerator for its structure)
+func (m MeLoID) meLoComponent(start int, end int) string {
+	if m.IsZero() {
+		return ""
+	}
+	return m.id[start:end]
+}
+
+// Landesziffern returns the country code (the first 2 characters, "DE")
+func (m MeLoID) Landesziffern() string {
+	return m.meLoComponent(0, 2)
+}
+
+// Netzbetreibernummer returns the 6 digits that identify the Netzbetreiber
+func (m MeLoID) Netzbetreibernummer() string {
+	return m.meLoComponent(2, 8)
+}
+
+// Postleitzahl returns the 5 digit postal code
+func (m MeLoID) Postleitzahl() string {
+	return m.meLoComponent(8, 13)
+}
+
+// LaufendeNummer returns the last 20 characters, the serial number
+func (m MeLoID) LaufendeNummer() string {
+	return m.meLoComponent(13, MeLoIdType.Length)
+}
+
+// A TRID is a valid Technische Ressourcen-ID. Create it with ParseTRID.
+type TRID struct{ typedId }
+
+// ParseTRID returns the TRID or an error if the value is not a valid TR-ID (structure and checksum)
+func ParseTRID(value string) (TRID, error) {
+	id, err := parseTypedId(TRIdType, value)
+	return TRID{id}, err
+}
+
+// UnmarshalText parses a TR-ID (implements encoding.TextUnmarshaler)
+func (t *TRID) UnmarshalText(text []byte) error {
+	return t.unmarshalText(TRIdType, text)
+}
+
+// Scan reads a TR-ID from a database column (implements sql.Scanner)
+func (t *TRID) Scan(src any) error {
+	return t.scan(TRIdType, src)
+}
+
+// Checksum returns the check digit (the last digit)
+func (t TRID) Checksum() string {
+	return t.checksum()
+}
+
+// WithoutChecksum returns the first 10 characters
+func (t TRID) WithoutChecksum() string {
+	return t.withoutChecksum()
+}
+
+// A SRID is a valid Steuerbare Ressourcen-ID. Create it with ParseSRID.
+type SRID struct{ typedId }
+
+// ParseSRID returns the SRID or an error if the value is not a valid SR-ID (structure and checksum)
+func ParseSRID(value string) (SRID, error) {
+	id, err := parseTypedId(SRIdType, value)
+	return SRID{id}, err
+}
+
+// UnmarshalText parses a SR-ID (implements encoding.TextUnmarshaler)
+func (s *SRID) UnmarshalText(text []byte) error {
+	return s.unmarshalText(SRIdType, text)
+}
+
+// Scan reads a SR-ID from a database column (implements sql.Scanner)
+func (s *SRID) Scan(src any) error {
+	return s.scan(SRIdType, src)
+}
+
+// Checksum returns the check digit (the last digit)
+func (s SRID) Checksum() string {
+	return s.checksum()
+}
+
+// WithoutChecksum returns the first 10 characters
+func (s SRID) WithoutChecksum() string {
+	return s.withoutChecksum()
+}
@@ -0,0 +1,133 @@
+package idgenerator_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/go-bo4e/enum/rollencodetyp"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+)
+
+// the typed IDs have to implement these interfaces
+var (
+	_ encoding.TextMarshaler   = idgenerator.MaLoID{}
+	_ encoding.TextUnmarshaler = &idgenerator.MeLoID{}
+	_ json.Marshaler           = idgenerator.NeLoID{}
+	_ sql.Scanner              = &idgenerator.TRID{}
+	_ driver.Valuer            = idgenerator.SRID{}
+)
+
+func (s *Suite) Test_Parse_Typed_Ids() {
+	malo, err := idgenerator.ParseMaLoID("41373559241")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), malo.String(), is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), malo.Checksum(), is.EqualTo("1"))
+	then.AssertThat(s.T(), malo.WithoutChecksum(), is.EqualTo("4137355924"))
+	then.AssertThat(s.T(), malo.Issuer(), is.EqualTo(rollencodetyp.BDEW))
+
+	checksum, err := idgenerator.MaLoIdType.CalculateChecksum("1234567890")
+	then.AssertThat(s.T(), err, is.Nil())
+	malo, err = idgenerator.ParseMaLoID("1234567890" + checksum)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), malo.Issuer(), is.EqualTo(rollencodetyp.DVGW))
+
+	nelo, err := idgenerator.ParseNeLoID(" e1137355921 ") // is normalized
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), nelo.String(), is.EqualTo("E1137355921"))
+	then.AssertThat(s.T(), nelo.WithoutChecksum(), is.EqualTo("E113735592"))
+
+	melo, err := idgenerator.ParseMeLoID("DE0010696664610000000000000012345")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), melo.Landesziffern(), is.EqualTo("DE"))
+	then.AssertThat(s.T(), melo.Netzbetreibernummer(), is.EqualTo("001069"))
+	then.AssertThat(s.T(), melo.Postleitzahl(), is.EqualTo("66646"))
+	then.AssertThat(s.T(), melo.LaufendeNummer(), is.EqualTo("10000000000000012345"))
+
+	for _, idType := range []idgenerator.IdType{idgenerator.TRIdType, idgenerator.SRIdType} {
+		id, generationErr := idType.NewRandomId()
+		then.AssertThat(s.T(), generationErr, is.Nil())
+		var parsed interface{ Checksum() string }
+		if idType.Name == idgenerator.TRIdType.Name {
+			parsed, err = idgenerator.ParseTRID(id)
+		} else {
+			parsed, err = idgenerator.ParseSRID(id)
+		}
+		then.AssertThat(s.T(), err, is.Nil())
+		then.AssertThat(s.T(), parsed.Checksum(), is.EqualTo(id[10:]))
+	}
+}
+
+func (s *Suite) Test_Parse_Rejects_Invalid_Ids() {
+	_, err := idgenerator.ParseMaLoID("41373559240")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	then.AssertThat(s.T(), err.Error(), is.StringContaining("checksum"))
+	_, err = idgenerator.ParseNeLoID("41373559241") // a MaLo
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	_, err = idgenerator.ParseMeLoID("DE00106966646")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	_, err = idgenerator.ParseSRID("")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+type typedIdsTestStruct struct {
+	MaLo idgenerator.MaLoID  `json:"malo"`
+	MeLo idgenerator.MeLoID  `json:"melo"`
+	NeLo *idgenerator.NeLoID `json:"nelo,omitempty"`
+}
+
+func (s *Suite) Test_Typed_Ids_Json() {
+	var parsed typedIdsTestStruct
+	err := json.Unmarshal([]byte(`{"malo": "41373559241", "melo": "DE0010696664610000000000000012345", "nelo": "E1137355921"}`), &parsed)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), parsed.MaLo.Issuer(), is.EqualTo(rollencodetyp.BDEW))
+	then.AssertThat(s.T(), parsed.NeLo.String(), is.EqualTo("E1137355921"))
+	marshalled, err := json.Marshal(parsed)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), string(marshalled), is.EqualTo(`{"malo":"41373559241","melo":"DE0010696664610000000000000012345","nelo":"E1137355921"}`))
+
+	err = json.Unmarshal([]byte(`{"malo": "41373559240"}`), &parsed)
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+
+	var empty typedIdsTestStruct
+	err = json.Unmarshal([]byte(`{"malo": "", "melo": null}`), &empty)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), empty.MaLo.IsZero(), is.True())
+	then.AssertThat(s.T(), empty.MeLo.IsZero(), is.True())
+	marshalled, err = json.Marshal(empty)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), string(marshalled), is.EqualTo(`{"malo":"","melo":""}`))
+}
+
+func (s *Suite) Test_Typed_Ids_Text() {
+	nelo, err := idgenerator.ParseNeLoID("E1137355921")
+	then.AssertThat(s.T(), err, is.Nil())
+	text, err := nelo.MarshalText()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), string(text), is.EqualTo("E1137355921"))
+	var unmarshalled idgenerator.NeLoID
+	then.AssertThat(s.T(), unmarshalled.UnmarshalText(text), is.Nil())
+	then.AssertThat(s.T(), unmarshalled, is.EqualTo(nelo))
+	then.AssertThat(s.T(), unmarshalled.UnmarshalText([]byte("E1137355920")), is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Typed_Ids_Sql() {
+	var malo idgenerator.MaLoID
+	then.AssertThat(s.T(), malo.Scan("41373559241"), is.Nil())
+	then.AssertThat(s.T(), malo.String(), is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), malo.Scan([]byte("41373559241")), is.Nil())
+	value, err := malo.Value()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), value, is.EqualTo[driver.Value]("41373559241"))
+
+	then.AssertThat(s.T(), malo.Scan("41373559240"), is.Not(is.Nil()))
+	then.AssertThat(s.T(), malo.Scan(int64(41373559241)), is.Not(is.Nil()))
+
+	then.AssertThat(s.T(), malo.Scan(nil), is.Nil()) // NULL
+	then.AssertThat(s.T(), malo.IsZero(), is.True())
+	value, err = malo.Value()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), value == nil, is.True())
+}
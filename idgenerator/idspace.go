@@ -0,0 +1,75 @@
+package idgenerator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// The ID space of an IdType are all of its valid IDs. Because the prefix is fixed and the checksum follows from the rest, each ID is
+// determined by its body (the characters in between). The body is a number in a mixed radix system: each position has its own alphabet.
+// This allows to number all IDs of a type from 0 to SpaceSize()-1.
+
+// SpaceSize returns the number of distinct valid IDs of this type
+func (t IdType) SpaceSize() *big.Int {
+	size := big.NewInt(1)
+	for _, alphabet := range t.bodyAlphabets {
+		size.Mul(size, big.NewInt(int64(len(alphabet))))
+	}
+	return size
+}
+
+// bodyOf returns the part of the id between the prefix and the checksum
+func (t IdType) bodyOf(id string) (string, error) {
+	if !t.pattern.MatchString(id) {
+		return "", fmt.Errorf("'%s' does not match the %s-ID pattern %s", id, t.Label, t.pattern.String())
+	}
+	body := strings.TrimPrefix(id, t.prefix)
+	if t.HasChecksum() {
+		body = body[:len(body)-1]
+	}
+	return body, nil
+}
+
+// IndexOf returns the position of the given ID in the ID space of this type. The checksum of the id is ignored.
+func (t IdType) IndexOf(id string) (*big.Int, error) {
+	body, err := t.bodyOf(id)
+	if err != nil {
+		return nil, err
+	}
+	index := big.NewInt(0)
+	for position, character := range body {
+		alphabet := t.bodyAlphabets[position]
+		digit := strings.IndexRune(alphabet, character)
+		if digit < 0 {
+			return nil, fmt.Errorf("'%c' at position %d of '%s' is not allowed in %s-IDs", character, position+len(t.prefix)+1, id, t.Label)
+		}
+		index.Mul(index, big.NewInt(int64(len(alphabet))))
+		index.Add(index, big.NewInt(int64(digit)))
+	}
+	return index, nil
+}
+
+// IdAt returns the complete ID (including the checksum) at the given position of the ID space of this type
+func (t IdType) IdAt(index *big.Int) (string, error) {
+	if index.Sign() < 0 || index.Cmp(t.SpaceSize()) >= 0 {
+		return "", fmt.Errorf("the index %s is out of the range of the %s-ID space [0, %s)", index.String(), t.Label, t.SpaceSize().String())
+	}
+	body := make([]byte, len(t.bodyAlphabets))
+	remainder := new(big.Int).Set(index)
+	digit := new(big.Int)
+	for position := len(t.bodyAlphabets) - 1; position >= 0; position-- {
+		alphabet := t.bodyAlphabets[position]
+		remainder.DivMod(remainder, big.NewInt(int64(len(alphabet))), digit)
+		body[position] = alphabet[digit.Int64()]
+	}
+	id := t.prefix + string(body)
+	if !t.HasChecksum() {
+		return id, nil
+	}
+	checksum, err := t.CalculateChecksum(id)
+	if err != nil {
+		return "", err
+	}
+	return id + checksum, nil
+}
@@ -0,0 +1,182 @@
+package idgenerator
+
+import (
+	"fmt"
+	"github.com/hochfrequenz/go-bo4e/bo"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// An IdType describes the structure of one of the supported ID types and how its checksum is calculated (if it has one).
+type IdType struct {
+	// Name is the value of the ID_TYPE_TO_GENERATE environment variable that selects this type, e.g. "MALO"
+	Name string
+	// Label is the short name that is used in the JSON responses ("type"), e.g. "MaLo"
+	Label string
+	// Length is the number of characters of a complete ID (including the checksum, if any)
+	Length int
+	// pattern is a regex that all IDs of this type must match (the checksum is not checked by the regex)
+	pattern *regexp.Regexp
+	// prefix is the fixed beginning of all IDs of this type, e.g. "E" for NeLo-IDs
+	prefix string
+	// bodyAlphabets are the allowed characters for each position between the prefix and the checksum
+	bodyAlphabets []string
+	// calculateChecksum returns the check digit for the first Length-1 characters of an ID; nil if the type has no checksum (MeLo)
+	calculateChecksum func(idWithoutChecksum string) (int, error)
+}
+
+const (
+	digits       = "0123456789"
+	alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// repeatAlphabet returns a slice that contains the given alphabet n times
+func repeatAlphabet(alphabet string, n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = alphabet
+	}
+	return result
+}
+
+var (
+	// MaLoIdType are Marktlokations-IDs
+	MaLoIdType = IdType{Name: "MALO", Label: "MaLo", Length: 11, pattern: regexp.MustCompile(`^[1-9]\d{10}$`), calculateChecksum: bo.CalculateMaLoIdCheckSum,
+		bodyAlphabets: append([]string{"123456789"}, repeatAlphabet(digits, 9)...)}
+	// NeLoIdType are Netzlokations-IDs
+	NeLoIdType = IdType{Name: "NELO", Label: "NeLo", Length: 11, pattern: regexp.MustCompile(`^E[A-Z\d]{9}\d$`), calculateChecksum: bo.GetNeLoIdCheckSum,
+		prefix: "E", bodyAlphabets: repeatAlphabet(alphanumeric, 9)}
+	// MeLoIdType are Messlokations-IDs; they have no checksum
+	MeLoIdType = IdType{Name: "MELO", Label: "MeLo", Length: 33, pattern: regexp.MustCompile(`^DE\d{11}[A-Z\d]{20}$`),
+		prefix: "DE", bodyAlphabets: append(repeatAlphabet(digits, 11), repeatAlphabet(alphanumeric, 20)...)}
+	// TRIdType are Technische Ressourcen-IDs
+	TRIdType = IdType{Name: "TRID", Label: "TR", Length: 11, pattern: regexp.MustCompile(`^D[A-Z\d]{9}\d$`), calculateChecksum: bo.GetTRIdCheckSum,
+		prefix: "D", bodyAlphabets: repeatAlphabet(alphanumeric, 9)}
+	// SRIdType are Steuerbare Ressourcen-IDs
+	SRIdType = IdType{Name: "SRID", Label: "SR", Length: 11, pattern: regexp.MustCompile(`^C[A-Z\d]{9}\d$`), calculateChecksum: bo.GetSRIdCheckSum,
+		prefix: "C", bodyAlphabets: repeatAlphabet(alphanumeric, 9)}
+)
+
+// supportedIdTypes are all ID types this service knows about (in the order in which they're listed in the README)
+var supportedIdTypes = []IdType{MaLoIdType, NeLoIdType, MeLoIdType, TRIdType, SRIdType}
+
+// getIdType returns the IdType with the given name (case-insensitive). Both the Name ("TRID") and the Label ("TR") are accepted.
+func getIdType(name string) (IdType, error) {
+	for _, idType := range supportedIdTypes {
+		if strings.EqualFold(idType.Name, name) || strings.EqualFold(idType.Label, name) {
+			return idType, nil
+		}
+	}
+	return IdType{}, fmt.Errorf("unsupported ID type '%s'. Supported values are 'MALO', 'NELO', 'MELO', 'TRID' and 'SRID'", name)
+}
+
+// detectIdType guesses the type of the given (possibly invalid) ID from its length and its first character(s).
+// The second return value is false if the ID does not look like any of the supported types.
+func detectIdType(id string) (IdType, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	switch {
+	case len(id) == MeLoIdType.Length && strings.HasPrefix(id, "DE"):
+		return MeLoIdType, true
+	case len(id) != 11:
+		return IdType{}, false
+	case id[0] >= '1' && id[0] <= '9':
+		return MaLoIdType, true
+	case id[0] == 'E':
+		return NeLoIdType, true
+	case id[0] == 'D':
+		return TRIdType, true
+	case id[0] == 'C':
+		return SRIdType, true
+	}
+	return IdType{}, false
+}
+
+// Generator returns the IdGenerator that generates IDs of this type
+func (t IdType) Generator() IdGenerator {
+	switch t.Name {
+	case MaLoIdType.Name:
+		return MaLoIdGenerator{}
+	case NeLoIdType.Name:
+		return NeLoIdGenerator{}
+	case MeLoIdType.Name:
+		return MeLoIdGenerator{}
+	case TRIdType.Name:
+		return TRIdGenerator{}
+	case SRIdType.Name:
+		return SRIdGenerator{}
+	}
+	panic(fmt.Sprintf("there is no generator for ID type '%s'", t.Name))
+}
+
+// NewRandomId returns a new random (and valid) ID of this type that is not on the exclusion list (see EXCLUSION_LIST_PATH).
+// Unlike the HTTP API, it does not record the ID in the reservation store.
+func (t IdType) NewRandomId() (string, error) {
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return "", err
+	}
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		id, generationErr := t.newUncheckedId()
+		if generationErr != nil {
+			return "", generationErr
+		}
+		if !exclusions.rejects(t.Label, id) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a %s-ID that is not on the exclusion list within %d attempts", t.Label, maxGenerationAttempts)
+}
+
+// newUncheckedId returns a new random (and valid) ID of this type without checking the exclusion list
+func (t IdType) newUncheckedId() (string, error) {
+	dictionary, err := t.Generator().generateIdDictionary()
+	if err != nil {
+		return "", err
+	}
+	return dictionary["id"], nil
+}
+
+// HasChecksum returns true iff the last character of IDs of this type is a check digit
+func (t IdType) HasChecksum() bool {
+	return t.calculateChecksum != nil
+}
+
+// CalculateChecksum returns the check digit for the given ID. The id may either be the ID without checksum or a complete ID (in which case its last character is ignored).
+func (t IdType) CalculateChecksum(id string) (string, error) {
+	if !t.HasChecksum() {
+		return "", fmt.Errorf("%s-IDs have no checksum", t.Label)
+	}
+	if len(id) == t.Length {
+		id = id[:t.Length-1]
+	}
+	if len(id) != t.Length-1 {
+		return "", fmt.Errorf("a %s-ID without checksum must be %d characters long but '%s' is %d characters long", t.Label, t.Length-1, id, len(id))
+	}
+	checksum, err := t.calculateChecksum(id)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(checksum), nil
+}
+
+// Validate returns nil if the given id is a valid ID of this type (structure and checksum). Otherwise, the error describes what's wrong.
+func (t IdType) Validate(id string) error {
+	if len(id) != t.Length {
+		return fmt.Errorf("a %s-ID must be %d characters long but '%s' is %d characters long", t.Label, t.Length, id, len(id))
+	}
+	if !t.pattern.MatchString(id) {
+		return fmt.Errorf("'%s' does not match the %s-ID pattern %s", id, t.Label, t.pattern.String())
+	}
+	if !t.HasChecksum() {
+		return nil
+	}
+	expectedChecksum, err := t.CalculateChecksum(id)
+	if err != nil {
+		return err
+	}
+	if actualChecksum := id[t.Length-1:]; actualChecksum != expectedChecksum {
+		return fmt.Errorf("the checksum of '%s' is '%s' but should be '%s'", id, actualChecksum, expectedChecksum)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package idgenerator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// httpInvocationsVariable enables the invocation payload format for HTTP triggers. Set it to "true" if enableForwardingHttpRequest is false in the host.json.
+const httpInvocationsVariable = "AZURE_FUNCTIONS_HTTP_INVOCATIONS"
+
+// invocationIdHeader is the header with which the Functions host marks its requests to the custom handler
+const invocationIdHeader = "X-Azure-Functions-InvocationId"
+
+// maxInvocationCount is the maximum number of IDs that a single invocation generates
+const maxInvocationCount = 1000
+
+// the names of the bindings in the function.json files
+const (
+	httpInputBinding     = "req"
+	httpOutputBinding    = "res"
+	requestInputBinding  = "request" // the queue message of the generate-queue function
+	idsOutputBinding     = "ids"
+	invocationLogsPrefix = "malo-id-generator: "
+)
+
+// An InvocationRequest is the payload with which the Functions host invokes a function of a custom handler (see https://learn.microsoft.com/en-us/azure/azure-functions/functions-custom-handlers#request-payload)
+type InvocationRequest struct {
+	Data     map[string]json.RawMessage `json:"Data"`     // Data contains the values of the input bindings by their names
+	Metadata map[string]json.RawMessage `json:"Metadata"` // Metadata contains the trigger metadata, e.g. the dequeue count of queue messages
+}
+
+// An InvocationResponse is the answer of the custom handler to an InvocationRequest
+type InvocationResponse struct {
+	Outputs     map[string]any `json:"Outputs"` // Outputs contains the values of the output bindings by their names
+	Logs        []string       `json:"Logs"`
+	ReturnValue any            `json:"ReturnValue,omitempty"`
+}
+
+// An invocationHttpRequest is the value of an HTTP trigger binding in an InvocationRequest
+type invocationHttpRequest struct {
+	Url     string              `json:"Url"`
+	Method  string              `json:"Method"`
+	Query   map[string]string   `json:"Query"`
+	Headers map[string][]string `json:"Headers"`
+	Params  map[string]string   `json:"Params"`
+	Body    string              `json:"Body"`
+}
+
+// An invocationHttpResponse is the value of an HTTP output binding in an InvocationResponse
+type invocationHttpResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Body       string            `json:"body"`
+	Headers    map[string]string `json:"headers"`
+}
+
+// an invocationFunction handles the invocations of a function with non-HTTP triggers
+type invocationFunction func(request InvocationRequest) (InvocationResponse, error)
+
+// invocationFunctions maps the names of the functions with non-HTTP triggers (i.e. the names of their folders) to their implementation
+var invocationFunctions = map[string]invocationFunction{
+	"generate-queue": generateInvocation,
+	"generate-timer": generateInvocation,
+}
+
+// invocationGenerateRequest are the optional parameters of generateInvocation (e.g. the queue message {"type": "NELO", "count": 10})
+type invocationGenerateRequest struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// parseInvocationGenerateRequest parses the "request" input binding. The host passes queue messages as JSON strings, so that JSON messages are encoded twice.
+func parseInvocationGenerateRequest(raw json.RawMessage) (invocationGenerateRequest, error) {
+	var request invocationGenerateRequest
+	if len(raw) == 0 {
+		return request, nil
+	}
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		raw = json.RawMessage(message)
+	}
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return request, fmt.Errorf("the '%s' binding has to be a JSON object like {\"type\": \"MALO\", \"count\": 10}: %w", requestInputBinding, err)
+	}
+	return request, nil
+}
+
+// generateInvocation generates IDs and writes them to the "ids" output binding. The type and count are read from the "request" input binding (if any); by default, a single ID of the configured ID_TYPE_TO_GENERATE is generated (e.g. by the timer trigger).
+func generateInvocation(invocation InvocationRequest) (InvocationResponse, error) {
+	request, err := parseInvocationGenerateRequest(invocation.Data[requestInputBinding])
+	if err != nil {
+		return InvocationResponse{}, err
+	}
+	if request.Count == 0 {
+		request.Count = 1
+	}
+	if request.Count < 0 || request.Count > maxInvocationCount {
+		return InvocationResponse{}, fmt.Errorf("the count has to be between 1 and %d but was %d", maxInvocationCount, request.Count)
+	}
+	idType, err := getIdTypeOrConfigured(request.Type)
+	if err != nil {
+		return InvocationResponse{}, err
+	}
+	generator := idType.Generator()
+	ids := make([]map[string]string, 0, request.Count)
+	for i := 0; i < request.Count; i++ {
+		rawId, generationErr := generateUnusedIdDictionary(generator)
+		if generationErr != nil {
+			return InvocationResponse{}, generationErr
+		}
+		ids = append(ids, rawId)
+	}
+	return InvocationResponse{
+		Outputs: map[string]any{idsOutputBinding: ids},
+		Logs:    []string{fmt.Sprintf("%sgenerated %d %s-ID(s)", invocationLogsPrefix, len(ids), idType.Label)},
+	}, nil
+}
+
+// invokeHttpFunction replays the HTTP request of an HTTP trigger binding with the handler and returns the response as HTTP output binding
+func invokeHttpFunction(handler http.Handler, binding invocationHttpRequest) (InvocationResponse, error) {
+	requestUrl, err := url.Parse(binding.Url)
+	if err != nil {
+		return InvocationResponse{}, fmt.Errorf("invalid URL '%s': %w", binding.Url, err)
+	}
+	query := requestUrl.Query()
+	for key, value := range binding.Query {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+	requestUrl.RawQuery = query.Encode()
+	request, err := http.NewRequest(binding.Method, requestUrl.RequestURI(), strings.NewReader(binding.Body))
+	if err != nil {
+		return InvocationResponse{}, err
+	}
+	for key, values := range binding.Headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	request.Header.Del(invocationIdHeader) // the replayed request must not be treated as invocation again
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	headers := make(map[string]string, len(recorder.Header()))
+	for key := range recorder.Header() {
+		headers[key] = recorder.Header().Get(key)
+	}
+	return InvocationResponse{
+		Outputs: map[string]any{httpOutputBinding: invocationHttpResponse{StatusCode: recorder.Code, Body: recorder.Body.String(), Headers: headers}},
+		Logs:    []string{fmt.Sprintf("%s%s %s returned %d", invocationLogsPrefix, binding.Method, requestUrl.Path, recorder.Code)},
+	}, nil
+}
+
+// httpBindingOf returns the HTTP trigger binding of the invocation, if it has one
+func httpBindingOf(invocation InvocationRequest) (invocationHttpRequest, bool) {
+	var binding invocationHttpRequest
+	raw, ok := invocation.Data[httpInputBinding]
+	if !ok || json.Unmarshal(raw, &binding) != nil || binding.Method == "" {
+		return binding, false
+	}
+	return binding, true
+}
+
+// InvokeFunction handles an invocation of the function with the given name: functions with non-HTTP triggers are looked up in the invocationFunctions, HTTP triggers are replayed with the handler
+func InvokeFunction(handler http.Handler, functionName string, invocation InvocationRequest) (InvocationResponse, error) {
+	if function, ok := invocationFunctions[functionName]; ok {
+		return function(invocation)
+	}
+	if binding, ok := httpBindingOf(invocation); ok {
+		return invokeHttpFunction(handler, binding)
+	}
+	return InvocationResponse{}, fmt.Errorf("unknown function '%s'", functionName)
+}
+
+// invocationHandler returns the handler for the invocations of the function with non-HTTP triggers that the host sends to /<function name>
+func invocationHandler(functionName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var invocation InvocationRequest
+		if err := c.ShouldBindJSON(&invocation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		response, err := InvokeFunction(nil, functionName, invocation)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, InvocationResponse{Logs: []string{invocationLogsPrefix + err.Error()}, Outputs: map[string]any{}})
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// httpInvocationMiddleware unwraps invocation payloads of HTTP triggers (if enabled with AZURE_FUNCTIONS_HTTP_INVOCATIONS), replays the original request with the router and wraps the response again.
+// All other requests are passed on unchanged.
+func httpInvocationMiddleware(router http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if os.Getenv(httpInvocationsVariable) != "true" || c.Request.Method != http.MethodPost || c.GetHeader(invocationIdHeader) == "" {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var invocation InvocationRequest
+		isHttpInvocation := false
+		if json.Unmarshal(body, &invocation) == nil {
+			_, isHttpInvocation = httpBindingOf(invocation)
+		}
+		if _, isFunction := invocationFunctions[strings.Trim(c.Request.URL.Path, "/")]; isFunction || !isHttpInvocation {
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			c.Next()
+			return
+		}
+		response, err := InvokeFunction(router, "", invocation)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusOK, response)
+	}
+}
+
+// invokeCommand runs an invocation payload (e.g. one recorded from the Functions host) locally and prints the response
+func invokeCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("invoke", stdout)
+	functionName := flagSet.String("function", "", "the name of the function (required for functions with non-HTTP triggers, e.g. generate-queue)")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	files, err := openInputFiles(flagSet.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	// gin logs the replayed requests to stdout, where they would clutter the output
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = io.Discard
+	defer func() { gin.DefaultWriter = defaultWriter }()
+	router := NewRouter()
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	for _, file := range files {
+		var invocation InvocationRequest
+		decodeErr := json.NewDecoder(file).Decode(&invocation)
+		_ = file.Close()
+		if decodeErr != nil {
+			return fmt.Errorf("could not read the invocation payload: %w", decodeErr)
+		}
+		response, invokeErr := InvokeFunction(router, *functionName, invocation)
+		if invokeErr != nil {
+			return invokeErr
+		}
+		if err = encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
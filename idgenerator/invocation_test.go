@@ -0,0 +1,101 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+)
+
+// readInvocation reads a recorded invocation payload from the testdata
+func (s *Suite) readInvocation(name string) []byte {
+	payload, err := os.ReadFile(filepath.Join("testdata", "invocations", name))
+	then.AssertThat(s.T(), err, is.Nil())
+	return payload
+}
+
+// performInvocation sends the payload to the path like the Functions host does
+func performInvocation(path string, payload []byte) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("POST", path, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Azure-Functions-InvocationId", "2a8f4c1e-7b3d-4e9a-b5c6-1d2e3f4a5b6c")
+	response := httptest.NewRecorder()
+	idgenerator.NewRouter().ServeHTTP(response, request)
+	return response
+}
+
+type invocationTestResponse struct {
+	Outputs struct {
+		Ids []map[string]string `json:"ids"`
+		Res struct {
+			StatusCode int               `json:"statusCode"`
+			Body       string            `json:"body"`
+			Headers    map[string]string `json:"headers"`
+		} `json:"res"`
+	} `json:"Outputs"`
+	Logs []string `json:"Logs"`
+}
+
+func (s *Suite) Test_Queue_Invocation() {
+	response := performInvocation("/generate-queue", s.readInvocation("generate-queue.json"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var invocationResponse invocationTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &invocationResponse), is.Nil())
+	then.AssertThat(s.T(), len(invocationResponse.Outputs.Ids), is.EqualTo(3))
+	for _, rawId := range invocationResponse.Outputs.Ids {
+		then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(rawId["id"]), is.Nil())
+	}
+	then.AssertThat(s.T(), len(invocationResponse.Logs), is.EqualTo(1))
+}
+
+func (s *Suite) Test_Timer_Invocation_Uses_The_Configured_Type() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "TRID")
+	response := performInvocation("/generate-timer", s.readInvocation("generate-timer.json"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var invocationResponse invocationTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &invocationResponse), is.Nil())
+	then.AssertThat(s.T(), len(invocationResponse.Outputs.Ids), is.EqualTo(1))
+	then.AssertThat(s.T(), idgenerator.TRIdType.Validate(invocationResponse.Outputs.Ids[0]["id"]), is.Nil())
+}
+
+func (s *Suite) Test_Invalid_Queue_Message() {
+	response := performInvocation("/generate-queue", []byte(`{"Data": {"request": "{\"count\": 100000}"}, "Metadata": {}}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusInternalServerError))
+}
+
+func (s *Suite) Test_Http_Invocation() {
+	s.T().Setenv("AZURE_FUNCTIONS_HTTP_INVOCATIONS", "true")
+	response := performInvocation("/suggest", s.readInvocation("http-suggest.json"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var invocationResponse invocationTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &invocationResponse), is.Nil())
+	then.AssertThat(s.T(), invocationResponse.Outputs.Res.StatusCode, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), invocationResponse.Outputs.Res.Headers["Content-Type"], is.StringContaining("application/json"))
+	then.AssertThat(s.T(), invocationResponse.Outputs.Res.Body, is.StringContaining(`"id":"41373559241"`))
+}
+
+func (s *Suite) Test_Http_Invocations_Are_Only_Unwrapped_If_Enabled() {
+	s.T().Setenv("AZURE_FUNCTIONS_HTTP_INVOCATIONS", "")
+	response := performInvocation("/suggest", s.readInvocation("http-suggest.json"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound)) // there is no POST /suggest
+}
+
+func (s *Suite) Test_Invoke_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"invoke", "-function", "generate-queue", filepath.Join("testdata", "invocations", "generate-queue.json")}, bytes.NewReader(nil), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	var invocationResponse invocationTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(stdout.Bytes(), &invocationResponse), is.Nil())
+	then.AssertThat(s.T(), len(invocationResponse.Outputs.Ids), is.EqualTo(3))
+
+	stdout.Reset()
+	exitCode = idgenerator.RunCli([]string{"invoke"}, bytes.NewReader(s.readInvocation("http-suggest.json")), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	then.AssertThat(s.T(), json.Unmarshal(stdout.Bytes(), &invocationResponse), is.Nil())
+	then.AssertThat(s.T(), invocationResponse.Outputs.Res.StatusCode, is.EqualTo(http.StatusOK))
+}
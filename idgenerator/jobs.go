@@ -0,0 +1,502 @@
+package idgenerator
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hochfrequenz/go-bo4e/bo"
+	"github.com/hochfrequenz/go-bo4e/enum/botyp"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jobDirectoryVariable is the environment variable that sets the directory in which the state and the results of the generation jobs are kept
+const jobDirectoryVariable = "JOB_DIRECTORY"
+
+// maxJobSize is the maximum number of IDs a single job may generate
+const maxJobSize = 1_000_000
+
+// jobProgressInterval is the number of generated IDs after which the progress of a job is persisted
+const jobProgressInterval = 10_000
+
+// ErrJobNotFound is returned if there is no job with the given ID
+var ErrJobNotFound = errors.New("the job does not exist")
+
+// the states of a Job
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// the supported result formats of a Job (and their file extension and content type)
+var jobFormats = map[string]struct{ extension, contentType string }{
+	"json":   {extension: "json", contentType: "application/json"},
+	"ndjson": {extension: "ndjson", contentType: "application/x-ndjson"},
+	"csv":    {extension: "csv", contentType: "text/csv"},
+	"bo4e":   {extension: "json", contentType: "application/json"},
+}
+
+// JobItem describes how many IDs of one type a job generates
+type JobItem struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// A JobSpec describes what a generation job generates
+type JobSpec struct {
+	Items []JobItem `json:"items" binding:"required"`
+	// Format of the result: "json" (an array of the same objects /json returns), "ndjson" (one such object per line), "csv" (type and id) or "bo4e" (an array of BO4E business objects, e.g. Marktlokationen)
+	Format string `json:"format,omitempty"`
+}
+
+// validate checks the spec, sets the default format and returns the total number of IDs
+func (spec *JobSpec) validate() (int, error) {
+	if spec.Format == "" {
+		spec.Format = "json"
+	}
+	if _, ok := jobFormats[spec.Format]; !ok {
+		return 0, fmt.Errorf("unsupported format '%s'. Supported formats are 'json', 'ndjson', 'csv' and 'bo4e'", spec.Format)
+	}
+	if len(spec.Items) == 0 {
+		return 0, fmt.Errorf("the spec has no items")
+	}
+	total := 0
+	for i, item := range spec.Items {
+		idType, err := getIdType(item.Type)
+		if err != nil {
+			return 0, err
+		}
+		spec.Items[i].Type = idType.Name
+		if item.Count < 1 {
+			return 0, fmt.Errorf("the count of item %d has to be positive", i+1)
+		}
+		if total += item.Count; total > maxJobSize {
+			return 0, fmt.Errorf("a job must not generate more than %d IDs", maxJobSize)
+		}
+	}
+	return total, nil
+}
+
+// A Job generates a large number of IDs in the background
+type Job struct {
+	Id         string     `json:"id"`
+	Spec       JobSpec    `json:"spec"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Generated  int        `json:"generated"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// A JobManager runs generation jobs and keeps their state and results as files in a local directory, so that a restart does not lose them.
+// Jobs that were interrupted by a restart are started again from scratch when the directory is opened: their partial result is discarded and all IDs are generated anew.
+// With a reservation store, the discarded IDs stay recorded, so they are never handed out (they are lost, but never duplicated).
+type JobManager struct {
+	directory string
+	mutex     sync.Mutex
+	jobs      map[string]*Job
+	running   sync.WaitGroup
+}
+
+// OpenJobManager loads all jobs from the directory (which is created if it does not exist) and restarts the unfinished ones
+func OpenJobManager(directory string) (*JobManager, error) {
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create the job directory: %w", err)
+	}
+	manager := &JobManager{directory: directory, jobs: map[string]*Job{}}
+	stateFiles, err := filepath.Glob(filepath.Join(directory, "*.job.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, stateFile := range stateFiles {
+		data, readErr := os.ReadFile(stateFile)
+		if readErr != nil {
+			return nil, readErr
+		}
+		var job Job
+		if readErr = json.Unmarshal(data, &job); readErr != nil {
+			return nil, fmt.Errorf("the job state '%s' is corrupt: %w", stateFile, readErr)
+		}
+		manager.jobs[job.Id] = &job
+		if job.Status == jobQueued || job.Status == jobRunning {
+			log.Printf("Restarting the job '%s' that was interrupted", job.Id)
+			manager.start(&job)
+		}
+	}
+	return manager, nil
+}
+
+func (m *JobManager) statePath(jobId string) string {
+	return filepath.Join(m.directory, jobId+".job.json")
+}
+
+func (m *JobManager) resultPath(job *Job) string {
+	return filepath.Join(m.directory, job.Id+".result."+jobFormats[job.Spec.Format].extension)
+}
+
+// save persists the state of the job (atomically, so that a crash never leaves a corrupt state file). The caller has to hold the mutex.
+func (m *JobManager) save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	temporaryPath := m.statePath(job.Id) + ".tmp"
+	if err = os.WriteFile(temporaryPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, m.statePath(job.Id))
+}
+
+// Submit validates the spec and starts a new job for it
+func (m *JobManager) Submit(spec JobSpec) (Job, error) {
+	total, err := spec.validate()
+	if err != nil {
+		return Job{}, err
+	}
+	jobId, err := newRandomToken()
+	if err != nil {
+		return Job{}, err
+	}
+	job := &Job{Id: jobId, Spec: spec, Status: jobQueued, Total: total, CreatedAt: time.Now().UTC()}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err = m.save(job); err != nil {
+		return Job{}, err
+	}
+	m.jobs[job.Id] = job
+	m.start(job)
+	return *job, nil
+}
+
+// start runs the job in the background
+func (m *JobManager) start(job *Job) {
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		err := m.run(job)
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		finishedAt := time.Now().UTC()
+		job.FinishedAt = &finishedAt
+		if err != nil {
+			job.Status = jobFailed
+			job.Error = err.Error()
+			_ = os.Remove(m.resultPath(job))
+		} else {
+			job.Status = jobDone
+		}
+		if saveErr := m.save(job); saveErr != nil {
+			log.Printf("Could not save the state of job '%s': %v", job.Id, saveErr)
+		}
+	}()
+}
+
+// updateProgress sets the status and the number of generated IDs of the job
+func (m *JobManager) updateProgress(job *Job, status string, generated int, persist bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	job.Status = status
+	job.Generated = generated
+	if !persist {
+		return nil
+	}
+	return m.save(job)
+}
+
+// run generates the IDs of the job into its result file
+func (m *JobManager) run(job *Job) error {
+	if err := m.updateProgress(job, jobRunning, 0, true); err != nil {
+		return err
+	}
+	file, err := os.Create(m.resultPath(job))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	buffer := bufio.NewWriter(file)
+	writer := newJobResultWriter(buffer, job.Spec.Format)
+	generated := 0
+	for _, item := range job.Spec.Items {
+		idType, typeErr := getIdType(item.Type)
+		if typeErr != nil {
+			return typeErr
+		}
+		generator := idType.Generator()
+		for remaining := item.Count; remaining > 0; {
+			// the IDs are generated (and recorded in the reservation store) in batches that end where the progress is persisted
+			batchSize := min(remaining, jobProgressInterval-generated%jobProgressInterval)
+			rawIds, generationErr := generateUnusedIdDictionaries(generator, batchSize)
+			if generationErr != nil {
+				return generationErr
+			}
+			for _, rawId := range rawIds {
+				if err = writer.write(idType, rawId); err != nil {
+					return err
+				}
+				generated++
+				if err = m.updateProgress(job, jobRunning, generated, generated%jobProgressInterval == 0); err != nil {
+					return err
+				}
+			}
+			remaining -= batchSize
+		}
+	}
+	if err = writer.close(); err != nil {
+		return err
+	}
+	if err = buffer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Wait blocks until all jobs that are currently running have finished
+func (m *JobManager) Wait() {
+	m.running.Wait()
+}
+
+// Get returns a snapshot of the job with the given ID
+func (m *JobManager) Get(jobId string) (Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	job, ok := m.jobs[jobId]
+	if !ok {
+		return Job{}, fmt.Errorf("'%s': %w", jobId, ErrJobNotFound)
+	}
+	return *job, nil
+}
+
+// List returns snapshots of all jobs, the newest first
+func (m *JobManager) List() []Job {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		result = append(result, *job)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// OpenResult opens the result file of a finished job
+func (m *JobManager) OpenResult(jobId string) (*os.File, Job, error) {
+	job, err := m.Get(jobId)
+	if err != nil {
+		return nil, job, err
+	}
+	if job.Status != jobDone {
+		return nil, job, fmt.Errorf("the job '%s' is %s", jobId, job.Status)
+	}
+	file, err := os.Open(m.resultPath(&job))
+	return file, job, err
+}
+
+// jobResultWriter writes the generated IDs in one of the jobFormats
+type jobResultWriter struct {
+	w         io.Writer
+	format    string
+	csvWriter *csv.Writer
+	count     int
+}
+
+func newJobResultWriter(w io.Writer, format string) *jobResultWriter {
+	writer := &jobResultWriter{w: w, format: format}
+	if format == "csv" {
+		writer.csvWriter = csv.NewWriter(w)
+	}
+	return writer
+}
+
+// newIdBusinessObject returns the BO4E business object (e.g. a Marktlokation) that is identified by the id
+func newIdBusinessObject(idType IdType, id string) (bo.BusinessObject, error) {
+	switch idType.Name {
+	case MaLoIdType.Name:
+		marktlokation := bo.NewBusinessObject(botyp.MARKTLOKATION).(*bo.Marktlokation)
+		marktlokation.MarktlokationsId = id
+		return marktlokation, nil
+	case MeLoIdType.Name:
+		messlokation := bo.NewBusinessObject(botyp.MESSLOKATION).(*bo.Messlokation)
+		messlokation.MesslokationsId = id
+		return messlokation, nil
+	case NeLoIdType.Name:
+		netzlokation := bo.NewBusinessObject(botyp.NETZLOKATION).(*bo.Netzlokation)
+		netzlokation.NetzlokationsId = &id
+		return netzlokation, nil
+	case TRIdType.Name:
+		technischeRessource := bo.NewBusinessObject(botyp.TECHNISCHERESSOURCE).(*bo.TechnischeRessource)
+		technischeRessource.TechnischeRessourceId = &id
+		return technischeRessource, nil
+	case SRIdType.Name:
+		steuerbareRessource := bo.NewBusinessObject(botyp.STEUERBARERESSOURCE).(*bo.SteuerbareRessource)
+		steuerbareRessource.SteuerbareRessourceId = id
+		return steuerbareRessource, nil
+	}
+	return nil, fmt.Errorf("there is no business object for %s-IDs", idType.Label)
+}
+
+func (w *jobResultWriter) write(idType IdType, rawId map[string]string) error {
+	if w.format == "csv" {
+		if w.count == 0 {
+			_ = w.csvWriter.Write([]string{"type", "id"})
+		}
+		w.count++
+		return w.csvWriter.Write([]string{rawId["type"], rawId["id"]})
+	}
+	var value any = rawId
+	if w.format == "bo4e" {
+		businessObject, err := newIdBusinessObject(idType, rawId["id"])
+		if err != nil {
+			return err
+		}
+		value = businessObject
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if w.format == "ndjson" {
+		_, err = w.w.Write(append(data, '\n'))
+		return err
+	}
+	separator := ",\n"
+	if w.count == 0 {
+		separator = "[\n"
+	}
+	w.count++
+	_, err = w.w.Write(append([]byte(separator), data...))
+	return err
+}
+
+func (w *jobResultWriter) close() error {
+	switch {
+	case w.format == "csv":
+		w.csvWriter.Flush()
+		return w.csvWriter.Error()
+	case w.format == "ndjson":
+		return nil
+	case w.count == 0:
+		_, err := io.WriteString(w.w, "[]\n")
+		return err
+	default:
+		_, err := io.WriteString(w.w, "\n]\n")
+		return err
+	}
+}
+
+var (
+	jobManagersMutex sync.Mutex
+	// jobManagers contains the managers that were already opened, by their directory, so that each directory is only opened once per process
+	jobManagers = map[string]*JobManager{}
+)
+
+// getJobManager returns the manager for the directory configured in the JOB_DIRECTORY environment variable (or a directory in the system's temp directory if it's not set)
+func getJobManager() (*JobManager, error) {
+	directory, ok := os.LookupEnv(jobDirectoryVariable)
+	if !ok || directory == "" {
+		directory = filepath.Join(os.TempDir(), "malo-id-generator-jobs")
+	}
+	jobManagersMutex.Lock()
+	defer jobManagersMutex.Unlock()
+	if manager, ok := jobManagers[directory]; ok {
+		return manager, nil
+	}
+	manager, err := OpenJobManager(directory)
+	if err != nil {
+		return nil, err
+	}
+	jobManagers[directory] = manager
+	return manager, nil
+}
+
+// getJobManagerOrAbort returns the job manager or writes an error response and returns nil
+func getJobManagerOrAbort(c *gin.Context) *JobManager {
+	manager, err := getJobManager()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil
+	}
+	return manager
+}
+
+// submitJobHandler starts a job for the JobSpec in the body and returns it (with its ID)
+func submitJobHandler(c *gin.Context) {
+	manager := getJobManagerOrAbort(c)
+	if manager == nil {
+		return
+	}
+	var spec JobSpec
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job, err := manager.Submit(spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+job.Id)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// listJobsHandler returns all jobs
+func listJobsHandler(c *gin.Context) {
+	manager := getJobManagerOrAbort(c)
+	if manager == nil {
+		return
+	}
+	c.JSON(http.StatusOK, manager.List())
+}
+
+// jobHandler returns the status and the progress of the job
+func jobHandler(c *gin.Context) {
+	manager := getJobManagerOrAbort(c)
+	if manager == nil {
+		return
+	}
+	job, err := manager.Get(c.Param("job"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// jobResultHandler returns the result file of a finished job
+func jobResultHandler(c *gin.Context) {
+	manager := getJobManagerOrAbort(c)
+	if manager == nil {
+		return
+	}
+	file, job, err := manager.OpenResult(c.Param("job"))
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	format := jobFormats[job.Spec.Format]
+	c.DataFromReader(http.StatusOK, info.Size(), format.contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s.%s"`, job.Id, format.extension),
+	})
+}
@@ -0,0 +1,133 @@
+package idgenerator_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (s *Suite) Test_Job_Generates_Csv_And_Survives_Restart() {
+	directory := s.T().TempDir()
+	manager, err := idgenerator.OpenJobManager(directory)
+	then.AssertThat(s.T(), err, is.Nil())
+	job, err := manager.Submit(idgenerator.JobSpec{Items: []idgenerator.JobItem{{Type: "malo", Count: 30}, {Type: "SR", Count: 20}}, Format: "csv"})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), job.Total, is.EqualTo(50))
+	manager.Wait()
+
+	// a new manager (e.g. after a restart) still knows the finished job and its result
+	manager, err = idgenerator.OpenJobManager(directory)
+	then.AssertThat(s.T(), err, is.Nil())
+	job, err = manager.Get(job.Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), job.Status, is.EqualTo("done"))
+	then.AssertThat(s.T(), job.Generated, is.EqualTo(50))
+	result, _, err := manager.OpenResult(job.Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = result.Close() }()
+	records, err := csv.NewReader(result).ReadAll()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(records), is.EqualTo(51))
+	then.AssertThat(s.T(), records[1][0], is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), records[50][0], is.EqualTo("SR"))
+	then.AssertThat(s.T(), idgenerator.SRIdType.Validate(records[50][1]), is.Nil())
+}
+
+func (s *Suite) Test_Interrupted_Job_Is_Restarted() {
+	directory := s.T().TempDir()
+	interrupted := `{"id":"interrupted","spec":{"items":[{"type":"NELO","count":5}],"format":"ndjson"},"status":"running","total":5,"generated":2,"createdAt":"2024-01-01T00:00:00Z"}`
+	then.AssertThat(s.T(), os.WriteFile(filepath.Join(directory, "interrupted.job.json"), []byte(interrupted), 0o600), is.Nil())
+	manager, err := idgenerator.OpenJobManager(directory)
+	then.AssertThat(s.T(), err, is.Nil())
+	manager.Wait()
+	result, job, err := manager.OpenResult("interrupted")
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = result.Close() }()
+	then.AssertThat(s.T(), job.Generated, is.EqualTo(5))
+	lines := 0
+	scanner := bufio.NewScanner(result)
+	for scanner.Scan() {
+		var rawId map[string]string
+		then.AssertThat(s.T(), json.Unmarshal(scanner.Bytes(), &rawId), is.Nil())
+		then.AssertThat(s.T(), rawId["type"], is.EqualTo("NeLo"))
+		lines++
+	}
+	then.AssertThat(s.T(), lines, is.EqualTo(5))
+}
+
+func (s *Suite) Test_Job_Records_Its_Ids_In_Batches() {
+	storePath := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	s.T().Setenv("RESERVATION_STORE_PATH", storePath)
+	manager, err := idgenerator.OpenJobManager(s.T().TempDir())
+	then.AssertThat(s.T(), err, is.Nil())
+	job, err := manager.Submit(idgenerator.JobSpec{Items: []idgenerator.JobItem{{Type: "MALO", Count: 25}}, Format: "csv"})
+	then.AssertThat(s.T(), err, is.Nil())
+	manager.Wait()
+	result, _, err := manager.OpenResult(job.Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = result.Close() }()
+	records, err := csv.NewReader(result).ReadAll()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(records), is.EqualTo(26))
+
+	// all IDs are recorded with a single write (and hence share the same time)
+	storeContent, err := os.ReadFile(storePath)
+	then.AssertThat(s.T(), err, is.Nil())
+	lines := strings.Split(strings.TrimSpace(string(storeContent)), "\n")
+	then.AssertThat(s.T(), len(lines), is.EqualTo(25))
+	times := map[string]bool{}
+	for _, line := range lines {
+		var record map[string]string
+		then.AssertThat(s.T(), json.Unmarshal([]byte(line), &record), is.Nil())
+		then.AssertThat(s.T(), record["action"], is.EqualTo("issue"))
+		times[record["time"]] = true
+	}
+	then.AssertThat(s.T(), len(times), is.EqualTo(1))
+	for _, record := range records[1:] {
+		then.AssertThat(s.T(), string(storeContent), is.StringContaining(`"id":"`+record[1]+`"`))
+	}
+}
+
+func (s *Suite) Test_Job_Endpoints_With_Bo4e_Result() {
+	s.T().Setenv("JOB_DIRECTORY", s.T().TempDir())
+	router := idgenerator.NewRouter()
+	response := performRequest(router, "POST", "/jobs", strings.NewReader(`{"items":[{"type":"MELO","count":3},{"type":"TRID","count":2}],"format":"bo4e"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusAccepted))
+	var job idgenerator.Job
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &job), is.Nil())
+	then.AssertThat(s.T(), response.Header().Get("Location"), is.EqualTo("/jobs/"+job.Id))
+
+	deadline := time.Now().Add(10 * time.Second)
+	for job.Status != "done" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		response = performGetRequest(router, "/jobs/"+job.Id)
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+		then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &job), is.Nil())
+	}
+	then.AssertThat(s.T(), job.Status, is.EqualTo("done"))
+	response = performGetRequest(router, "/jobs/"+job.Id+"/result")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	body, _ := io.ReadAll(response.Body)
+	var businessObjects []map[string]any
+	then.AssertThat(s.T(), json.Unmarshal(body, &businessObjects), is.Nil())
+	then.AssertThat(s.T(), len(businessObjects), is.EqualTo(5))
+	then.AssertThat(s.T(), businessObjects[0]["boTyp"], is.EqualTo[any]("MESSLOKATION"))
+	then.AssertThat(s.T(), len(businessObjects[0]["messlokationsId"].(string)), is.EqualTo(33))
+	then.AssertThat(s.T(), businessObjects[4]["boTyp"], is.EqualTo[any]("TECHNISCHERESSOURCE"))
+
+	response = performGetRequest(router, "/jobs")
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), job.Id), is.True())
+	response = performGetRequest(router, "/jobs/does-not-exist/result")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+	response = performRequest(router, "POST", "/jobs", strings.NewReader(`{"items":[{"type":"MALO","count":1}],"format":"xml"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
@@ -0,0 +1,472 @@
+package idgenerator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// the error codes defined by the JSON-RPC 2.0 specification (https://www.jsonrpc.org/specification#error_object)
+const (
+	jsonRpcParseError     = -32700
+	jsonRpcInvalidRequest = -32600
+	jsonRpcMethodNotFound = -32601
+	jsonRpcInvalidParams  = -32602
+	jsonRpcInternalError  = -32603
+)
+
+// jsonRpcListMethod is the method that lists all tools with their descriptions and JSON schemas
+const jsonRpcListMethod = "tools.list"
+
+// maxRpcGenerateCount is the maximum number of IDs of a single call of the generate tool
+const maxRpcGenerateCount = 100
+
+// maxJsonRpcMessageSize is the maximum size of a single request (and line on stdin)
+const maxJsonRpcMessageSize = 1 << 20
+
+// A jsonRpcRequest is a JSON-RPC 2.0 request. Requests without an id are notifications, which are not answered.
+type jsonRpcRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// A JsonRpcError is the error object of a JSON-RPC 2.0 response
+type JsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// A jsonRpcResponse is a JSON-RPC 2.0 response; exactly one of Result and Error is set
+type jsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *JsonRpcError   `json:"error,omitempty"`
+}
+
+// An RpcTool is a method that can be called via JSON-RPC. Its parameters and result are described by JSON schemas, so that automation agents and editor plugins can discover how to call it.
+type RpcTool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	ParamsSchema map[string]any `json:"paramsSchema"`
+	ResultSchema map[string]any `json:"resultSchema"`
+	call         func(params json.RawMessage) (any, error)
+}
+
+// rpcParams are the parameters of all tools (not every tool uses every parameter; the schemas describe which ones are allowed)
+type rpcParams struct {
+	Type  string `json:"type"`
+	Id    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// rpcParamsError is returned by the tools if the parameters are invalid (as opposed to internal errors)
+type rpcParamsError struct {
+	err error
+}
+
+func (e rpcParamsError) Error() string {
+	return e.err.Error()
+}
+
+// characterClass returns a regex character class for the alphabet, e.g. "[1-9]" or "[0-9A-Z]"
+func characterClass(alphabet string) string {
+	var class strings.Builder
+	for start := 0; start < len(alphabet); {
+		end := start
+		for end+1 < len(alphabet) && alphabet[end+1] == alphabet[end]+1 {
+			end++
+		}
+		class.WriteByte(alphabet[start])
+		if end > start {
+			class.WriteByte('-')
+			class.WriteByte(alphabet[end])
+		}
+		start = end + 1
+	}
+	return "[" + class.String() + "]"
+}
+
+// idWithoutChecksumPattern returns a regex for the IDs of the type without their checksum, built from the prefix and the alphabets of the type
+func idWithoutChecksumPattern(idType IdType) string {
+	pattern := "^" + idType.prefix
+	for i := 0; i < len(idType.bodyAlphabets); {
+		repetitions := 1
+		for i+repetitions < len(idType.bodyAlphabets) && idType.bodyAlphabets[i+repetitions] == idType.bodyAlphabets[i] {
+			repetitions++
+		}
+		pattern += characterClass(idType.bodyAlphabets[i])
+		if repetitions > 1 {
+			pattern += fmt.Sprintf("{%d}", repetitions)
+		}
+		i += repetitions
+	}
+	return pattern + "$"
+}
+
+// idTypeSchema returns the schema of the "type" parameter: the names of all supported types
+func idTypeSchema(description string) map[string]any {
+	names := make([]any, 0, len(supportedIdTypes))
+	for _, idType := range supportedIdTypes {
+		names = append(names, idType.Name)
+	}
+	return map[string]any{"type": "string", "enum": names, "description": description}
+}
+
+// idSchema returns the schema of an ID of any of the given types; withoutChecksum also allows the IDs without their checksum
+func idSchema(idTypes []IdType, withoutChecksum bool, description string) map[string]any {
+	var alternatives []any
+	for _, idType := range idTypes {
+		alternatives = append(alternatives, map[string]any{"title": idType.Label, "type": "string", "pattern": idType.pattern.String()})
+		if withoutChecksum && idType.HasChecksum() {
+			alternatives = append(alternatives, map[string]any{"title": idType.Label + " without checksum", "type": "string", "pattern": idWithoutChecksumPattern(idType)})
+		}
+	}
+	return map[string]any{"description": description, "anyOf": alternatives}
+}
+
+// objectSchema returns the schema of an object with the given properties
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// stringSchema returns the schema of a string property
+func stringSchema(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// integerSchema returns the schema of an integer property
+func integerSchema(description string) map[string]any {
+	return map[string]any{"type": "integer", "description": description}
+}
+
+// checksumIdTypes returns the types that have a checksum
+func checksumIdTypes() []IdType {
+	var result []IdType
+	for _, idType := range supportedIdTypes {
+		if idType.HasChecksum() {
+			result = append(result, idType)
+		}
+	}
+	return result
+}
+
+// getRpcIdType returns the type from the params or, if it's not given, the type detected from the id (which may lack its checksum)
+func getRpcIdType(params rpcParams, id string) (IdType, error) {
+	if params.Type != "" {
+		idType, err := getIdType(params.Type)
+		if err != nil {
+			return IdType{}, rpcParamsError{err}
+		}
+		return idType, nil
+	}
+	if idType, ok := detectIdType(id); ok {
+		return idType, nil
+	}
+	if idType, ok := detectIdType(id + "0"); ok && idType.HasChecksum() {
+		return idType, nil
+	}
+	return IdType{}, rpcParamsError{fmt.Errorf("could not detect the type of '%s'; please provide the 'type' parameter", id)}
+}
+
+// rpcTools returns all tools. The schemas are derived from the supportedIdTypes.
+func rpcTools() []RpcTool {
+	typeLabels := make([]any, 0, len(supportedIdTypes))
+	for _, idType := range supportedIdTypes {
+		typeLabels = append(typeLabels, idType.Label)
+	}
+	labelSchema := map[string]any{"type": "string", "enum": typeLabels, "description": "the short name of the type"}
+	anyIdSchema := idSchema(supportedIdTypes, false, "a complete ID")
+	generatedIdSchema := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"id": anyIdSchema, "type": labelSchema, "checksum": stringSchema("the check digit (not set for MeLo-IDs)")},
+		"required":             []string{"id", "type"},
+		"additionalProperties": map[string]any{"type": "string", "description": "the components of the ID, e.g. the issuer of MaLo-IDs or the postleitzahl of MeLo-IDs"},
+	}
+	return []RpcTool{
+		{
+			Name:        "generate",
+			Description: "generates new random IDs with a valid checksum (the same objects as the /json endpoint)",
+			ParamsSchema: objectSchema(map[string]any{
+				"type":  idTypeSchema("the type of the IDs (default: the configured ID_TYPE_TO_GENERATE)"),
+				"count": map[string]any{"type": "integer", "minimum": 1, "maximum": maxRpcGenerateCount, "default": 1, "description": "the number of IDs"},
+			}),
+			ResultSchema: map[string]any{"type": "array", "items": generatedIdSchema},
+			call:         callGenerateTool,
+		},
+		{
+			Name:        "validate",
+			Description: "checks whether a value is a valid ID and, if only the checksum is wrong, suggests the corrected ID",
+			ParamsSchema: objectSchema(map[string]any{
+				"id":   stringSchema("the value to validate"),
+				"type": idTypeSchema("the expected type (default: detected from the value)"),
+			}, "id"),
+			ResultSchema: objectSchema(map[string]any{
+				"row":               integerSchema("always 0 for single values"),
+				"value":             stringSchema("the validated value"),
+				"type":              labelSchema,
+				"valid":             map[string]any{"type": "boolean"},
+				"reason":            stringSchema("why the value is invalid"),
+				"correctedChecksum": stringSchema("set if the value has the correct structure but the wrong checksum"),
+				"correctedId":       stringSchema("the value with the correctedChecksum"),
+			}, "row", "value", "valid"),
+			call: callValidateTool,
+		},
+		{
+			Name:        "explain",
+			Description: "calculates the checksum of an ID step by step",
+			ParamsSchema: objectSchema(map[string]any{
+				"id":   idSchema(checksumIdTypes(), true, "a complete ID or an ID without checksum"),
+				"type": idTypeSchema("the type of the ID (default: detected from the ID)"),
+			}, "id"),
+			ResultSchema: objectSchema(map[string]any{
+				"type":              labelSchema,
+				"id":                stringSchema("the complete ID with the correct checksum"),
+				"idWithoutChecksum": stringSchema("the ID without checksum"),
+				"steps": map[string]any{"type": "array", "items": objectSchema(map[string]any{
+					"position":  integerSchema("1-based position of the character"),
+					"character": stringSchema("the character at this position"),
+					"value":     integerSchema("the digit itself or, for letters, its ASCII code"),
+					"weight":    integerSchema("1 for odd and 2 for even positions"),
+					"product":   integerSchema("value * weight"),
+				})},
+				"oddSum":           integerSchema("the sum of the values on odd positions"),
+				"evenSum":          integerSchema("the sum of the values on even positions"),
+				"weightedEvenSum":  integerSchema("2 * evenSum"),
+				"sum":              integerSchema("oddSum + weightedEvenSum"),
+				"remainder":        integerSchema("sum modulo 10"),
+				"nextMultipleOf10": integerSchema("the next multiple of 10"),
+				"checksum":         stringSchema("the correct checksum"),
+				"givenChecksum":    stringSchema("the checksum of the given ID, if a complete ID was given"),
+			}, "type", "id", "idWithoutChecksum", "steps", "checksum"),
+			call: callExplainTool,
+		},
+		{
+			Name:        "checksum",
+			Description: "calculates the check digit of an ID (without the step by step explanation)",
+			ParamsSchema: objectSchema(map[string]any{
+				"id":   idSchema(checksumIdTypes(), true, "an ID without checksum or a complete ID (whose checksum is ignored)"),
+				"type": idTypeSchema("the type of the ID (default: detected from the ID)"),
+			}, "id"),
+			ResultSchema: objectSchema(map[string]any{
+				"type":              labelSchema,
+				"idWithoutChecksum": stringSchema("the ID without checksum"),
+				"checksum":          stringSchema("the correct checksum"),
+				"id":                stringSchema("the complete ID with the correct checksum"),
+			}, "type", "idWithoutChecksum", "checksum", "id"),
+			call: callChecksumTool,
+		},
+	}
+}
+
+// parseRpcParams parses the params of a tool call; missing params are allowed
+func parseRpcParams(raw json.RawMessage) (rpcParams, error) {
+	var params rpcParams
+	if len(raw) == 0 || string(raw) == "null" {
+		return params, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return params, rpcParamsError{fmt.Errorf("the params have to be an object with the properties described by the schema: %w", err)}
+	}
+	return params, nil
+}
+
+func callGenerateTool(raw json.RawMessage) (any, error) {
+	params, err := parseRpcParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	if params.Count == 0 {
+		params.Count = 1
+	}
+	if params.Count < 0 || params.Count > maxRpcGenerateCount {
+		return nil, rpcParamsError{fmt.Errorf("the count has to be between 1 and %d", maxRpcGenerateCount)}
+	}
+	idType, err := getIdTypeOrConfigured(params.Type)
+	if err != nil {
+		return nil, rpcParamsError{err}
+	}
+	generator := idType.Generator()
+	result := make([]map[string]string, 0, params.Count)
+	for i := 0; i < params.Count; i++ {
+		rawId, generationErr := generateUnusedIdDictionary(generator)
+		if generationErr != nil {
+			return nil, generationErr
+		}
+		result = append(result, rawId)
+	}
+	return result, nil
+}
+
+func callValidateTool(raw json.RawMessage) (any, error) {
+	params, err := parseRpcParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	var idType *IdType
+	if params.Type != "" {
+		requestedType, typeErr := getIdType(params.Type)
+		if typeErr != nil {
+			return nil, rpcParamsError{typeErr}
+		}
+		idType = &requestedType
+	}
+	return ValidateValue(idType, params.Id), nil
+}
+
+func callExplainTool(raw json.RawMessage) (any, error) {
+	params, err := parseRpcParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.ToUpper(strings.TrimSpace(params.Id))
+	idType, err := getRpcIdType(params, id)
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := ExplainChecksum(idType, id)
+	if err != nil {
+		return nil, rpcParamsError{err}
+	}
+	return explanation, nil
+}
+
+func callChecksumTool(raw json.RawMessage) (any, error) {
+	params, err := parseRpcParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.ToUpper(strings.TrimSpace(params.Id))
+	idType, err := getRpcIdType(params, id)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := idType.CalculateChecksum(id)
+	if err != nil {
+		return nil, rpcParamsError{err}
+	}
+	idWithoutChecksum := id[:idType.Length-1]
+	return map[string]string{"type": idType.Label, "idWithoutChecksum": idWithoutChecksum, "checksum": checksum, "id": idWithoutChecksum + checksum}, nil
+}
+
+// handleJsonRpcRequest executes a single request and returns its response or nil for notifications
+func handleJsonRpcRequest(raw json.RawMessage) *jsonRpcResponse {
+	var request jsonRpcRequest
+	if err := json.Unmarshal(raw, &request); err != nil || request.JsonRpc != "2.0" || request.Method == "" {
+		return &jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &JsonRpcError{Code: jsonRpcInvalidRequest, Message: "the request has to be an object with \"jsonrpc\": \"2.0\" and a \"method\""}}
+	}
+	response := &jsonRpcResponse{JsonRpc: "2.0", Id: request.Id}
+	if request.Method == jsonRpcListMethod {
+		response.Result = rpcTools()
+	} else {
+		response.Error = &JsonRpcError{Code: jsonRpcMethodNotFound, Message: fmt.Sprintf("unknown method '%s'. Use '%s' to list the available tools", request.Method, jsonRpcListMethod)}
+		for _, tool := range rpcTools() {
+			if tool.Name != request.Method {
+				continue
+			}
+			response.Error = nil
+			result, err := tool.call(request.Params)
+			switch err.(type) {
+			case nil:
+				response.Result = result
+			case rpcParamsError:
+				response.Error = &JsonRpcError{Code: jsonRpcInvalidParams, Message: err.Error()}
+			default:
+				response.Error = &JsonRpcError{Code: jsonRpcInternalError, Message: err.Error()}
+			}
+		}
+	}
+	if len(request.Id) == 0 {
+		return nil
+	}
+	return response
+}
+
+// HandleJsonRpc executes a JSON-RPC 2.0 request or batch of requests and returns the serialized response (nil if there is nothing to respond, i.e. only notifications)
+func HandleJsonRpc(message []byte) []byte {
+	message = bytes.TrimSpace(message)
+	var result any
+	if len(message) > 0 && message[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(message, &batch); err != nil {
+			result = jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &JsonRpcError{Code: jsonRpcParseError, Message: err.Error()}}
+		} else if len(batch) == 0 {
+			result = jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &JsonRpcError{Code: jsonRpcInvalidRequest, Message: "the batch is empty"}}
+		} else {
+			var responses []*jsonRpcResponse
+			for _, request := range batch {
+				if response := handleJsonRpcRequest(request); response != nil {
+					responses = append(responses, response)
+				}
+			}
+			if len(responses) == 0 {
+				return nil
+			}
+			result = responses
+		}
+	} else if !json.Valid(message) {
+		result = jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &JsonRpcError{Code: jsonRpcParseError, Message: "the request is not valid JSON"}}
+	} else {
+		response := handleJsonRpcRequest(message)
+		if response == nil {
+			return nil
+		}
+		result = response
+	}
+	serialized, err := json.Marshal(result)
+	if err != nil {
+		serialized, _ = json.Marshal(jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &JsonRpcError{Code: jsonRpcInternalError, Message: err.Error()}})
+	}
+	return serialized
+}
+
+// jsonRpcHandler is the HTTP transport of the JSON-RPC tools: the body of the POST request is a request or batch
+func jsonRpcHandler(c *gin.Context) {
+	message, err := io.ReadAll(io.LimitReader(c.Request.Body, maxJsonRpcMessageSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	response := HandleJsonRpc(message)
+	if response == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", response)
+}
+
+// rpcCommand is the stdio transport of the JSON-RPC tools: each line of stdin is a request (or batch) and each response is written as a single line to stdout
+func rpcCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("rpc", stdout)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJsonRpcMessageSize)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		response := HandleJsonRpc(scanner.Bytes())
+		if response == nil {
+			continue
+		}
+		if _, err := stdout.Write(append(response, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
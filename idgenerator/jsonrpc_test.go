@@ -0,0 +1,138 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type jsonRpcTestResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *Suite) callJsonRpc(request string) jsonRpcTestResponse {
+	var response jsonRpcTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(idgenerator.HandleJsonRpc([]byte(request)), &response), is.Nil())
+	then.AssertThat(s.T(), response.JsonRpc, is.EqualTo("2.0"))
+	return response
+}
+
+func (s *Suite) Test_JsonRpc_Generate() {
+	response := s.callJsonRpc(`{"jsonrpc": "2.0", "id": 7, "method": "generate", "params": {"type": "SRID", "count": 3}}`)
+	then.AssertThat(s.T(), response.Error == nil, is.True())
+	then.AssertThat(s.T(), string(response.Id), is.EqualTo("7"))
+	var ids []JsonResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Result, &ids), is.Nil())
+	then.AssertThat(s.T(), len(ids), is.EqualTo(3))
+	for _, id := range ids {
+		then.AssertThat(s.T(), idgenerator.SRIdType.Validate(id.Id), is.Nil())
+	}
+}
+
+func (s *Suite) Test_JsonRpc_Checksum_And_Explain() {
+	response := s.callJsonRpc(`{"jsonrpc": "2.0", "id": "a", "method": "checksum", "params": {"id": "E113735592"}}`)
+	then.AssertThat(s.T(), response.Error == nil, is.True())
+	then.AssertThat(s.T(), string(response.Result), is.StringContaining(`"id":"E1137355921"`))
+
+	response = s.callJsonRpc(`{"jsonrpc": "2.0", "id": "b", "method": "explain", "params": {"id": "41373559241"}}`)
+	then.AssertThat(s.T(), response.Error == nil, is.True())
+	var explanation idgenerator.ChecksumExplanation
+	then.AssertThat(s.T(), json.Unmarshal(response.Result, &explanation), is.Nil())
+	then.AssertThat(s.T(), explanation.GivenChecksumIsCorrect(), is.True())
+}
+
+func (s *Suite) Test_JsonRpc_Validate() {
+	response := s.callJsonRpc(`{"jsonrpc": "2.0", "id": 1, "method": "validate", "params": {"id": "41373559240"}}`)
+	var result idgenerator.ValidationResult
+	then.AssertThat(s.T(), json.Unmarshal(response.Result, &result), is.Nil())
+	then.AssertThat(s.T(), result.Valid, is.False())
+	then.AssertThat(s.T(), result.CorrectedId, is.EqualTo("41373559241"))
+}
+
+func (s *Suite) Test_JsonRpc_Errors() {
+	for request, expectedCode := range map[string]int{
+		`{"jsonrpc": "2.0", "id": 1, "method": "foo"}`:                                          -32601,
+		`{"jsonrpc": "2.0", "id": 1, "method": "generate", "params": {"count": 1000000}}`:       -32602,
+		`{"jsonrpc": "2.0", "id": 1, "method": "validate", "params": {"unknown": "parameter"}}`: -32602,
+		`{"jsonrpc": "2.0", "id": 1, "method": "explain", "params": {"id": "DE00106966646"}}`:   -32602,
+		`{"id": 1, "method": "generate"}`:                                                       -32600,
+		`{"jsonrpc": "2.0", "id": 1, `:                                                          -32700,
+	} {
+		response := s.callJsonRpc(request)
+		then.AssertThat(s.T(), response.Error != nil, is.True())
+		then.AssertThat(s.T(), response.Error.Code, is.EqualTo(expectedCode))
+	}
+}
+
+func (s *Suite) Test_JsonRpc_Notifications_Are_Not_Answered() {
+	then.AssertThat(s.T(), idgenerator.HandleJsonRpc([]byte(`{"jsonrpc": "2.0", "method": "generate", "params": {"type": "MALO"}}`)) == nil, is.True())
+	response := performRequest(idgenerator.NewRouter(), "POST", "/rpc", strings.NewReader(`[{"jsonrpc": "2.0", "method": "generate", "params": {"type": "MALO"}}]`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNoContent))
+}
+
+func (s *Suite) Test_JsonRpc_Batch_Over_Http() {
+	response := performRequest(idgenerator.NewRouter(), "POST", "/rpc", strings.NewReader(`[
+		{"jsonrpc": "2.0", "id": 1, "method": "validate", "params": {"id": "E1137355921"}},
+		{"jsonrpc": "2.0", "method": "generate"},
+		{"jsonrpc": "2.0", "id": 2, "method": "checksum", "params": {"id": "4137355924", "type": "MALO"}}
+	]`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var responses []jsonRpcTestResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &responses), is.Nil())
+	then.AssertThat(s.T(), len(responses), is.EqualTo(2))
+	then.AssertThat(s.T(), string(responses[1].Result), is.StringContaining(`"checksum":"1"`))
+}
+
+func (s *Suite) Test_JsonRpc_Tool_Schemas_Match_The_Id_Types() {
+	response := s.callJsonRpc(`{"jsonrpc": "2.0", "id": 1, "method": "tools.list"}`)
+	var tools []struct {
+		Name         string `json:"name"`
+		ParamsSchema struct {
+			Properties map[string]struct {
+				AnyOf []struct {
+					Title   string `json:"title"`
+					Pattern string `json:"pattern"`
+				} `json:"anyOf"`
+			} `json:"properties"`
+		} `json:"paramsSchema"`
+	}
+	then.AssertThat(s.T(), json.Unmarshal(response.Result, &tools), is.Nil())
+	then.AssertThat(s.T(), len(tools), is.EqualTo(4))
+	examples := map[string]string{"MaLo": "41373559241", "NeLo": "E1137355921", "MaLo without checksum": "4137355924", "NeLo without checksum": "E113735592"}
+	for _, tool := range tools {
+		if tool.Name != "checksum" {
+			continue
+		}
+		for _, alternative := range tool.ParamsSchema.Properties["id"].AnyOf {
+			if example, ok := examples[alternative.Title]; ok {
+				then.AssertThat(s.T(), regexp.MustCompile(alternative.Pattern).MatchString(example), is.True())
+			}
+		}
+	}
+}
+
+func (s *Suite) Test_JsonRpc_Stdio() {
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "checksum", "params": {"id": "4137355924"}}
+
+{"jsonrpc": "2.0", "method": "generate"}
+{"jsonrpc": "2.0", "id": 2, "method": "validate", "params": {"id": "41373559241"}}
+`)
+	exitCode := idgenerator.RunCli([]string{"rpc"}, stdin, &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	then.AssertThat(s.T(), len(lines), is.EqualTo(2))
+	then.AssertThat(s.T(), lines[0], is.StringContaining(`"id":"41373559241"`))
+	then.AssertThat(s.T(), lines[1], is.StringContaining(`"valid":true`))
+}
@@ -0,0 +1,109 @@
+package idgenerator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// feistelRounds is the number of rounds of the Feistel network. More than 8 rounds are recommended for format preserving encryption.
+const feistelRounds = 10
+
+// A keyedPermutation is a bijection of the integers [0, domain) that is determined by a secret key.
+// Without the key, the output looks random; with the key, it can be inverted.
+// It's a balanced Feistel network (with HMAC-SHA256 as round function) on the smallest even number of bits that covers the domain,
+// combined with cycle walking: outputs that fall outside of the domain are encrypted again until they're inside.
+type keyedPermutation struct {
+	key      []byte
+	domain   *big.Int
+	halfBits uint
+}
+
+// newKeyedPermutation returns a keyedPermutation of [0, domain). The tweak separates permutations that use the same key (e.g. for different ID types).
+func newKeyedPermutation(key []byte, tweak string, domain *big.Int) (keyedPermutation, error) {
+	if len(key) == 0 {
+		return keyedPermutation{}, fmt.Errorf("the key must not be empty")
+	}
+	if domain.Cmp(big.NewInt(2)) < 0 {
+		return keyedPermutation{}, fmt.Errorf("the domain must contain at least 2 elements")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(tweak))
+	bits := uint(new(big.Int).Sub(domain, big.NewInt(1)).BitLen())
+	if bits%2 == 1 {
+		bits++
+	}
+	return keyedPermutation{key: mac.Sum(nil), domain: domain, halfBits: bits / 2}, nil
+}
+
+// roundFunction returns a pseudo random number with halfBits bits that depends on the key, the round and the input
+func (p keyedPermutation) roundFunction(round int, input *big.Int) *big.Int {
+	var output []byte
+	for block := 0; len(output)*8 < int(p.halfBits); block++ {
+		mac := hmac.New(sha256.New, p.key)
+		mac.Write([]byte{byte(round), byte(block)})
+		mac.Write(input.Bytes())
+		output = append(output, mac.Sum(nil)...)
+	}
+	result := new(big.Int).SetBytes(output)
+	return result.And(result, p.halfMask())
+}
+
+func (p keyedPermutation) halfMask() *big.Int {
+	mask := new(big.Int).Lsh(big.NewInt(1), p.halfBits)
+	return mask.Sub(mask, big.NewInt(1))
+}
+
+// split returns the upper and lower half of x
+func (p keyedPermutation) split(x *big.Int) (*big.Int, *big.Int) {
+	left := new(big.Int).Rsh(x, p.halfBits)
+	right := new(big.Int).And(x, p.halfMask())
+	return left, right
+}
+
+// join is the inverse of split
+func (p keyedPermutation) join(left, right *big.Int) *big.Int {
+	result := new(big.Int).Lsh(left, p.halfBits)
+	return result.Or(result, right)
+}
+
+func (p keyedPermutation) encryptOnce(x *big.Int) *big.Int {
+	left, right := p.split(x)
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, new(big.Int).Xor(left, p.roundFunction(round, right))
+	}
+	return p.join(left, right)
+}
+
+func (p keyedPermutation) decryptOnce(y *big.Int) *big.Int {
+	left, right := p.split(y)
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = new(big.Int).Xor(right, p.roundFunction(round, left)), left
+	}
+	return p.join(left, right)
+}
+
+// Permute maps x from [0, domain) to another number in [0, domain); distinct inputs always have distinct outputs
+func (p keyedPermutation) Permute(x *big.Int) (*big.Int, error) {
+	if x.Sign() < 0 || x.Cmp(p.domain) >= 0 {
+		return nil, fmt.Errorf("%s is out of the range [0, %s)", x.String(), p.domain.String())
+	}
+	y := p.encryptOnce(x)
+	for y.Cmp(p.domain) >= 0 {
+		y = p.encryptOnce(y)
+	}
+	return y, nil
+}
+
+// Invert is the inverse of Permute
+func (p keyedPermutation) Invert(y *big.Int) (*big.Int, error) {
+	if y.Sign() < 0 || y.Cmp(p.domain) >= 0 {
+		return nil, fmt.Errorf("%s is out of the range [0, %s)", y.String(), p.domain.String())
+	}
+	x := p.decryptOnce(y)
+	for x.Cmp(p.domain) >= 0 {
+		x = p.decryptOnce(x)
+	}
+	return x, nil
+}
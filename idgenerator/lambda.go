@@ -0,0 +1,176 @@
+package idgenerator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// serverlessRuntimeVariable is the environment variable that selects how the router is served
+const serverlessRuntimeVariable = "SERVERLESS_RUNTIME"
+
+// the supported values of SERVERLESS_RUNTIME
+const (
+	azureRuntime  = "azure"  // azureRuntime (the default) serves the router via HTTP, as Azure Functions custom handler or standalone server
+	lambdaRuntime = "lambda" // lambdaRuntime runs the router as AWS Lambda handler for API Gateway (REST and HTTP APIs) and Function URL events
+)
+
+// runRouter serves the router with the runtime that is configured in SERVERLESS_RUNTIME
+func runRouter(router *gin.Engine) error {
+	switch runtime := os.Getenv(serverlessRuntimeVariable); runtime {
+	case "", azureRuntime:
+		return router.Run(getPort())
+	case lambdaRuntime:
+		lambda.Start(NewLambdaHandler(router))
+		return nil
+	default:
+		return fmt.Errorf("unsupported value of environment variable '%s': '%s'. Supported values are '%s' and '%s'", serverlessRuntimeVariable, runtime, azureRuntime, lambdaRuntime)
+	}
+}
+
+// lambdaEventVersion is used to distinguish the event formats: API Gateway REST APIs send version 1.0 events (without version field), HTTP APIs and Function URLs version 2.0 events
+type lambdaEventVersion struct {
+	Version string `json:"version"`
+}
+
+// NewLambdaHandler returns a Lambda handler that translates API Gateway and Function URL events to requests of the handler and its responses back to the respective response format
+func NewLambdaHandler(handler http.Handler) func(ctx context.Context, event json.RawMessage) (any, error) {
+	return func(ctx context.Context, event json.RawMessage) (any, error) {
+		var version lambdaEventVersion
+		if err := json.Unmarshal(event, &version); err != nil {
+			return nil, fmt.Errorf("the event is not a JSON object: %w", err)
+		}
+		if version.Version == "2.0" {
+			var request events.APIGatewayV2HTTPRequest
+			if err := json.Unmarshal(event, &request); err != nil {
+				return nil, err
+			}
+			return serveLambdaV2Request(ctx, handler, request)
+		}
+		var request events.APIGatewayProxyRequest
+		if err := json.Unmarshal(event, &request); err != nil {
+			return nil, err
+		}
+		if request.HTTPMethod == "" {
+			return nil, fmt.Errorf("unsupported event; only API Gateway and Function URL events are supported")
+		}
+		return serveLambdaV1Request(ctx, handler, request)
+	}
+}
+
+// decodeLambdaBody returns the body of the event, which is base64 encoded for binary content
+func decodeLambdaBody(body string, isBase64Encoded bool) (io.Reader, error) {
+	if !isBase64Encoded {
+		return strings.NewReader(body), nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("the body is not base64 encoded: %w", err)
+	}
+	return strings.NewReader(string(decoded)), nil
+}
+
+// isTextualContentType returns true if responses with this content type can be returned as plain string; all others (e.g. images and fonts) are base64 encoded
+func isTextualContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType == ""
+	}
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") || mediaType == "application/javascript"
+}
+
+// encodeLambdaBody returns the body of the response and whether it's base64 encoded
+func encodeLambdaBody(recorder *httptest.ResponseRecorder) (string, bool) {
+	if isTextualContentType(recorder.Header().Get("Content-Type")) {
+		return recorder.Body.String(), false
+	}
+	return base64.StdEncoding.EncodeToString(recorder.Body.Bytes()), true
+}
+
+// serveLambdaV1Request serves an API Gateway REST API event
+func serveLambdaV1Request(ctx context.Context, handler http.Handler, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	body, err := decodeLambdaBody(event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	query := url.Values{}
+	for key, values := range event.MultiValueQueryStringParameters {
+		query[key] = values
+	}
+	for key, value := range event.QueryStringParameters {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, event.HTTPMethod, (&url.URL{Path: event.Path, RawQuery: query.Encode()}).RequestURI(), body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	for key, values := range event.MultiValueHeaders {
+		request.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	for key, value := range event.Headers {
+		if request.Header.Get(key) == "" {
+			request.Header.Set(key, value)
+		}
+	}
+	request.RemoteAddr = event.RequestContext.Identity.SourceIP
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	responseBody, isBase64Encoded := encodeLambdaBody(recorder)
+	return events.APIGatewayProxyResponse{StatusCode: recorder.Code, MultiValueHeaders: recorder.Header(), Body: responseBody, IsBase64Encoded: isBase64Encoded}, nil
+}
+
+// serveLambdaV2Request serves an API Gateway HTTP API or Function URL event
+func serveLambdaV2Request(ctx context.Context, handler http.Handler, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	body, err := decodeLambdaBody(event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{}, err
+	}
+	path := event.RawPath
+	if stage := event.RequestContext.Stage; stage != "" && stage != "$default" {
+		// HTTP APIs with named stages include the stage in the path (but not if they are called via a custom domain, so "/explain" must not lose its "/ex" for the stage "ex")
+		if path == "/"+stage {
+			path = "/"
+		} else if strings.HasPrefix(path, "/"+stage+"/") {
+			path = strings.TrimPrefix(path, "/"+stage)
+		}
+	}
+	requestUri := path
+	if event.RawQueryString != "" {
+		requestUri += "?" + event.RawQueryString
+	}
+	request, err := http.NewRequestWithContext(ctx, event.RequestContext.HTTP.Method, requestUri, body)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{}, err
+	}
+	for key, value := range event.Headers {
+		// version 2.0 events combine multiple values of the same header with commas
+		request.Header.Set(key, value)
+	}
+	if len(event.Cookies) > 0 {
+		request.Header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+	request.RemoteAddr = event.RequestContext.HTTP.SourceIP
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	responseBody, isBase64Encoded := encodeLambdaBody(recorder)
+	headers := make(map[string]string, len(recorder.Header()))
+	for key, values := range recorder.Header() {
+		if key != "Set-Cookie" {
+			headers[key] = strings.Join(values, ",")
+		}
+	}
+	return events.APIGatewayV2HTTPResponse{StatusCode: recorder.Code, Headers: headers, Body: responseBody, IsBase64Encoded: isBase64Encoded, Cookies: recorder.Header().Values("Set-Cookie")}, nil
+}
@@ -0,0 +1,66 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// invokeLambda passes a recorded event to the Lambda handler and returns the JSON encoded response like the Lambda runtime does
+func (s *Suite) invokeLambda(name string) []byte {
+	event, err := os.ReadFile(filepath.Join("testdata", "lambda", name))
+	then.AssertThat(s.T(), err, is.Nil())
+	response, err := idgenerator.NewLambdaHandler(idgenerator.NewRouter())(context.Background(), event)
+	then.AssertThat(s.T(), err, is.Nil())
+	encoded, err := json.Marshal(response)
+	then.AssertThat(s.T(), err, is.Nil())
+	return encoded
+}
+
+func (s *Suite) Test_Lambda_Api_Gateway_Rest_Event() {
+	var response events.APIGatewayProxyResponse
+	then.AssertThat(s.T(), json.Unmarshal(s.invokeLambda("apigateway-rest.json"), &response), is.Nil())
+	then.AssertThat(s.T(), response.StatusCode, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.IsBase64Encoded, is.False())
+	then.AssertThat(s.T(), response.MultiValueHeaders["Content-Type"][0], is.StringContaining("application/json"))
+	then.AssertThat(s.T(), response.Body, is.StringContaining(`"id":"41373559241"`))
+}
+
+func (s *Suite) Test_Lambda_Http_Api_Event_With_Stage_And_Base64_Body() {
+	var response events.APIGatewayV2HTTPResponse
+	then.AssertThat(s.T(), json.Unmarshal(s.invokeLambda("http-api.json"), &response), is.Nil())
+	then.AssertThat(s.T(), response.StatusCode, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Headers["Content-Type"], is.StringContaining("application/json"))
+	then.AssertThat(s.T(), response.Body, is.StringContaining(`"valid":true`))
+}
+
+func (s *Suite) Test_Lambda_Http_Api_Event_Keeps_Paths_That_Only_Start_Like_The_Stage() {
+	// via a custom domain, the path does not contain the stage "ex", so "/explain" has to stay "/explain"
+	var response events.APIGatewayV2HTTPResponse
+	then.AssertThat(s.T(), json.Unmarshal(s.invokeLambda("http-api-custom-domain.json"), &response), is.Nil())
+	then.AssertThat(s.T(), response.StatusCode, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body, is.StringContaining(`"checksum":"1"`))
+}
+
+func (s *Suite) Test_Lambda_Function_Url_Event_With_Binary_Response() {
+	var response events.APIGatewayV2HTTPResponse
+	then.AssertThat(s.T(), json.Unmarshal(s.invokeLambda("function-url.json"), &response), is.Nil())
+	then.AssertThat(s.T(), response.StatusCode, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.IsBase64Encoded, is.True())
+	favicon, err := base64.StdEncoding.DecodeString(response.Body)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), bytes.HasPrefix(favicon, []byte("\x89PNG")), is.True())
+}
+
+func (s *Suite) Test_Lambda_Rejects_Other_Events() {
+	_, err := idgenerator.NewLambdaHandler(idgenerator.NewRouter())(context.Background(), []byte(`{"Records": []}`))
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
@@ -0,0 +1,304 @@
+package idgenerator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// defaultLeaseTtl is used if a lease is requested or renewed without a TTL
+const defaultLeaseTtl = time.Hour
+
+// maxLeaseTtl is the longest TTL a lease may have; longer test runs have to renew their lease
+const maxLeaseTtl = 7 * 24 * time.Hour
+
+// ErrLeaseNotFound is returned if there is no lease with the given ID
+var ErrLeaseNotFound = errors.New("the lease does not exist")
+
+// ErrLeaseExpired is returned if a lease that has expired or was released is renewed or released
+var ErrLeaseExpired = errors.New("the lease has expired or was released")
+
+// A Lease is a group of IDs that is reserved for a limited time (e.g. for a test run). When the lease expires or is released, its IDs go back to the pool and may be leased again.
+type Lease struct {
+	Id         string     `json:"id"`
+	Type       string     `json:"type"`
+	Owner      string     `json:"owner,omitempty"`
+	Ids        []string   `json:"ids"`
+	LeasedAt   time.Time  `json:"leasedAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// isActive returns true if the lease was neither released nor has it expired at the given time
+func (l *Lease) isActive(now time.Time) bool {
+	return l.ReleasedAt == nil && l.ExpiresAt.After(now)
+}
+
+// newRandomToken returns a random, URL-safe ID (e.g. for leases)
+func newRandomToken() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// applyLease updates the in-memory state with a lease record
+func (s *ReservationStore) applyLease(record storeRecord) {
+	switch record.Action {
+	case leaseAction:
+		lease := &Lease{Id: record.Lease, Type: record.Type, Owner: record.Owner, Ids: record.Ids, LeasedAt: record.Time, ExpiresAt: *record.ExpiresAt}
+		s.leases[lease.Id] = lease
+		for _, id := range record.Ids {
+			expiresAt := lease.ExpiresAt
+			s.knownIds[id] = record.Type
+			s.reservations[id] = &Reservation{Id: id, Type: record.Type, Owner: record.Owner, ReservedAt: record.Time, Lease: lease.Id, ExpiresAt: &expiresAt}
+		}
+	case renewAction, releaseLeaseAction:
+		lease, ok := s.leases[record.Lease]
+		if !ok {
+			return
+		}
+		if record.Action == renewAction {
+			lease.ExpiresAt = *record.ExpiresAt
+		} else {
+			releasedAt := record.Time
+			lease.ReleasedAt = &releasedAt
+		}
+		for _, id := range lease.Ids {
+			// IDs of an expired lease may already belong to a newer lease
+			if reservation, ok := s.reservations[id]; ok && reservation.Lease == lease.Id {
+				expiresAt := lease.ExpiresAt
+				reservation.ExpiresAt = &expiresAt
+				if lease.ReleasedAt != nil {
+					reservation.ReleasedAt = lease.ReleasedAt
+				}
+			}
+		}
+	}
+}
+
+// pooledIds returns up to count IDs of the given type whose lease has expired or was released (ordered by ID).
+// IDs that were added to the exclusion list since they were leased are not handed out again.
+func (s *ReservationStore) pooledIds(idType IdType, count int, now time.Time, exclusions *ExclusionList) []string {
+	var result []string
+	for id, reservation := range s.reservations {
+		if reservation.Lease != "" && reservation.Type == idType.Label && !reservation.isActive(now) && !exclusions.rejects(idType.Label, id) {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	if len(result) > count {
+		result = result[:count]
+	}
+	return result
+}
+
+// Lease leases count IDs of the given type for the ttl. IDs from expired or released leases (that are not on the exclusion list) are reused first; the others are generated and were never handed out before.
+func (s *ReservationStore) Lease(idType IdType, count int, owner string, ttl time.Duration) (Lease, error) {
+	if ttl <= 0 || ttl > maxLeaseTtl {
+		return Lease{}, fmt.Errorf("the TTL has to be positive and must not exceed %s", maxLeaseTtl)
+	}
+	leaseId, err := newRandomToken()
+	if err != nil {
+		return Lease{}, err
+	}
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return Lease{}, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	ids := s.pooledIds(idType, count, now, exclusions)
+	newIds := map[string]bool{}
+	for len(ids) < count {
+		id, generationErr := s.generateUnknownId(idType, newIds)
+		if generationErr != nil {
+			return Lease{}, generationErr
+		}
+		newIds[id] = true
+		ids = append(ids, id)
+	}
+	expiresAt := now.Add(ttl)
+	if err = s.write(storeRecord{Action: leaseAction, Lease: leaseId, Type: idType.Label, Owner: owner, Ids: ids, Time: now, ExpiresAt: &expiresAt}); err != nil {
+		return Lease{}, err
+	}
+	return *s.leases[leaseId], nil
+}
+
+// activeLease returns the lease with the given ID if it's still active
+func (s *ReservationStore) activeLease(leaseId string, now time.Time) (*Lease, error) {
+	lease, ok := s.leases[leaseId]
+	if !ok {
+		return nil, fmt.Errorf("'%s': %w", leaseId, ErrLeaseNotFound)
+	}
+	if !lease.isActive(now) {
+		return nil, fmt.Errorf("'%s': %w", leaseId, ErrLeaseExpired)
+	}
+	return lease, nil
+}
+
+// RenewLease extends the active lease with the given ID to expire ttl from now
+func (s *ReservationStore) RenewLease(leaseId string, ttl time.Duration) (Lease, error) {
+	if ttl <= 0 || ttl > maxLeaseTtl {
+		return Lease{}, fmt.Errorf("the TTL has to be positive and must not exceed %s", maxLeaseTtl)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	if _, err := s.activeLease(leaseId, now); err != nil {
+		return Lease{}, err
+	}
+	expiresAt := now.Add(ttl)
+	if err := s.write(storeRecord{Action: renewAction, Lease: leaseId, Time: now, ExpiresAt: &expiresAt}); err != nil {
+		return Lease{}, err
+	}
+	return *s.leases[leaseId], nil
+}
+
+// ReleaseLease releases the active lease with the given ID before it expires, so that its IDs go back to the pool
+func (s *ReservationStore) ReleaseLease(leaseId string) (Lease, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	if _, err := s.activeLease(leaseId, now); err != nil {
+		return Lease{}, err
+	}
+	if err := s.write(storeRecord{Action: releaseLeaseAction, Lease: leaseId, Time: now}); err != nil {
+		return Lease{}, err
+	}
+	return *s.leases[leaseId], nil
+}
+
+// GetLease returns the lease with the given ID (regardless of whether it's still active)
+func (s *ReservationStore) GetLease(leaseId string) (Lease, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lease, ok := s.leases[leaseId]
+	if !ok {
+		return Lease{}, fmt.Errorf("'%s': %w", leaseId, ErrLeaseNotFound)
+	}
+	return *lease, nil
+}
+
+// LeaseRequest is the body of the POST requests to /lease and /lease/:lease/renew
+type LeaseRequest struct {
+	Type  string `json:"type,omitempty"`  // Type of the IDs to lease; defaults to the configured ID_TYPE_TO_GENERATE
+	Count int    `json:"count,omitempty"` // Count is the number of IDs to lease; defaults to 1
+	Owner string `json:"owner,omitempty"`
+	Ttl   string `json:"ttl,omitempty"` // Ttl is a duration like "30m" or "2h"; defaults to 1h
+}
+
+// getTtl parses the TTL of the request or returns the default TTL if it's empty
+func (r LeaseRequest) getTtl() (time.Duration, error) {
+	if r.Ttl == "" {
+		return defaultLeaseTtl, nil
+	}
+	ttl, err := time.ParseDuration(r.Ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TTL '%s': %w", r.Ttl, err)
+	}
+	return ttl, nil
+}
+
+// bindOptionalJson binds the JSON body of the request (if there is one) to target
+func bindOptionalJson(c *gin.Context, target any) error {
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	return c.ShouldBindJSON(target)
+}
+
+// leaseHandler leases count IDs of the given type for the TTL
+func leaseHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	var request LeaseRequest
+	if err := bindOptionalJson(c, &request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ttl, err := request.getTtl()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Count == 0 {
+		request.Count = 1
+	}
+	if request.Count < 0 || request.Count > maxReservationCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the 'count' has to be between 1 and %d", maxReservationCount)})
+		return
+	}
+	idType, err := getIdTypeOrConfiguredFor(c, request.Type)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lease, err := store.Lease(idType, request.Count, request.Owner, ttl)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, lease)
+}
+
+// getLeaseHandler returns the lease with the ID from the path
+func getLeaseHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	lease, err := store.GetLease(c.Param("lease"))
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lease)
+}
+
+// renewLeaseHandler extends the lease with the ID from the path by the TTL from the (optional) body
+func renewLeaseHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	var request LeaseRequest
+	if err := bindOptionalJson(c, &request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ttl, err := request.getTtl()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lease, err := store.RenewLease(c.Param("lease"), ttl)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lease)
+}
+
+// releaseLeaseHandler releases the lease with the ID from the path
+func releaseLeaseHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	lease, err := store.ReleaseLease(c.Param("lease"))
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lease)
+}
@@ -0,0 +1,99 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (s *Suite) Test_Expired_Leases_Go_Back_To_The_Pool() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	expiring, err := store.Lease(idgenerator.SRIdType, 2, "test-run-1", 50*time.Millisecond)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(expiring.Ids), is.EqualTo(2))
+	released, err := store.Lease(idgenerator.SRIdType, 1, "test-run-2", time.Hour)
+	then.AssertThat(s.T(), err, is.Nil())
+	_, err = store.ReleaseLease(released.Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(store.Reservations("")), is.EqualTo(2))
+
+	time.Sleep(60 * time.Millisecond)
+	then.AssertThat(s.T(), len(store.Reservations("")), is.EqualTo(0))
+	_, err = store.RenewLease(expiring.Id, time.Hour)
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrLeaseExpired), is.True())
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	// after a restart, the 3 pooled IDs are leased again before new ones are generated
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	lease, err := store.Lease(idgenerator.SRIdType, 4, "test-run-3", time.Hour)
+	then.AssertThat(s.T(), err, is.Nil())
+	pooledIds := append(append([]string{}, expiring.Ids...), released.Ids...)
+	for _, id := range pooledIds {
+		then.AssertThat(s.T(), lease.Ids, is.ArrayContaining(id))
+	}
+	then.AssertThat(s.T(), len(store.Reservations("test-run-3")), is.EqualTo(4))
+	// the old leases don't affect the IDs of the new one
+	_, err = store.ReleaseLease(expiring.Id)
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrLeaseExpired), is.True())
+	then.AssertThat(s.T(), len(store.Reservations("test-run-3")), is.EqualTo(4))
+}
+
+func (s *Suite) Test_Excluded_Ids_Do_Not_Go_Back_To_The_Pool() {
+	store, err := idgenerator.OpenReservationStore(filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	released, err := store.Lease(idgenerator.NeLoIdType, 2, "test-run-1", time.Hour)
+	then.AssertThat(s.T(), err, is.Nil())
+	_, err = store.ReleaseLease(released.Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	// one of the IDs became a real one after it was leased
+	exclusionListPath := filepath.Join(s.T().TempDir(), "exclusions.txt")
+	then.AssertThat(s.T(), os.WriteFile(exclusionListPath, []byte(released.Ids[0]+"\n"), 0o600), is.Nil())
+	s.T().Setenv("EXCLUSION_LIST_PATH", exclusionListPath)
+	lease, err := store.Lease(idgenerator.NeLoIdType, 2, "test-run-2", time.Hour)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), lease.Ids, is.ArrayContaining(released.Ids[1]))
+	then.AssertThat(s.T(), lease.Ids, is.Not(is.ArrayContaining(released.Ids[0])))
+}
+
+func (s *Suite) Test_Lease_Endpoints() {
+	s.T().Setenv("RESERVATION_STORE_PATH", filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	router := idgenerator.NewRouter()
+	response := performRequest(router, "POST", "/lease", strings.NewReader(`{"count":3,"ttl":"10m","owner":"team-a"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusCreated))
+	var lease idgenerator.Lease
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &lease), is.Nil())
+	then.AssertThat(s.T(), len(lease.Ids), is.EqualTo(3))
+	then.AssertThat(s.T(), lease.Type, is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), lease.ExpiresAt.Sub(lease.LeasedAt), is.EqualTo(10*time.Minute))
+
+	response = performRequest(router, "POST", "/lease/"+lease.Id+"/renew", strings.NewReader(`{"ttl":"2h"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var renewed idgenerator.Lease
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &renewed), is.Nil())
+	then.AssertThat(s.T(), renewed.ExpiresAt.After(lease.ExpiresAt), is.True())
+
+	response = performRequest(router, "POST", "/lease/"+lease.Id+"/release", nil)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	response = performRequest(router, "POST", "/lease/"+lease.Id+"/release", nil)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusGone))
+	response = performGetRequest(router, "/lease/"+lease.Id)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), `"releasedAt"`), is.True())
+	response = performGetRequest(router, "/lease/does-not-exist")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+	response = performRequest(router, "POST", "/lease", strings.NewReader(`{"ttl":"forever"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
@@ -0,0 +1,358 @@
+package idgenerator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"math/big"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxNamespaces is the number of namespaces the ID space of each type is divided into. The n-th ID of the namespace in slot k is the ID at counter n*maxNamespaces+k of the
+// keyed sequence (see SequenceGenerator), so IDs of different namespaces never overlap. Even for MaLo-IDs, this leaves more than 8 million IDs per slot.
+// Released namespaces free their slot for new namespaces, which continue after the sequence numbers that were already used in the slot.
+const maxNamespaces = 1024
+
+// namespacePattern restricts namespaces to names that can be used in URL paths, e.g. team names or CI job IDs
+var namespacePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// ErrNamespaceNotFound is returned if a namespace that has not consumed any IDs yet (or was released) is queried
+var ErrNamespaceNotFound = errors.New("the namespace does not exist")
+
+// ErrSequenceNotIssued is returned if the ID of a sequence number is queried that the namespace has not handed out (yet), e.g. because it was skipped
+var ErrSequenceNotIssued = errors.New("the namespace has not issued an ID with this sequence number")
+
+// NamespaceUsage describes which IDs a namespace has consumed
+type NamespaceUsage struct {
+	Namespace string                    `json:"namespace"`
+	Slot      int64                     `json:"slot"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	Consumed  map[string]int64          `json:"consumed"` // Consumed maps each ID type to the number of sequence numbers the namespace has used (which is also the next sequence number)
+	offsets   map[string]int64          // offsets maps each ID type to the number of sequence numbers that released namespaces used in the same slot before
+	skipped   map[string]map[int64]bool // skipped maps each ID type to the sequence numbers whose ID was not handed out because it was already known or excluded
+}
+
+// copy returns a deep copy of the usage
+func (u *NamespaceUsage) copy() NamespaceUsage {
+	consumed := make(map[string]int64, len(u.Consumed))
+	for typeLabel, count := range u.Consumed {
+		consumed[typeLabel] = count
+	}
+	return NamespaceUsage{Namespace: u.Namespace, Slot: u.Slot, CreatedAt: u.CreatedAt, Consumed: consumed, offsets: u.offsets}
+}
+
+// counter returns the counter of the sequence at which the sequence-th ID of the given type of the namespace is found
+func (u *NamespaceUsage) counter(typeLabel string, sequence int64) *big.Int {
+	return namespaceCounter(u.Slot, u.offsets[typeLabel]+sequence)
+}
+
+// skip records the sequence numbers of the consumption that have no ID. Records that were written without their sequence numbers are assumed to have skipped none.
+func (u *NamespaceUsage) skip(record storeRecord) {
+	if len(record.Sequences) == 0 {
+		return
+	}
+	issued := make(map[int64]bool, len(record.Sequences))
+	for _, sequence := range record.Sequences {
+		issued[sequence] = true
+	}
+	for sequence := u.Consumed[record.Type]; sequence < record.Number; sequence++ {
+		if issued[sequence] {
+			continue
+		}
+		if u.skipped[record.Type] == nil {
+			u.skipped[record.Type] = map[int64]bool{}
+		}
+		u.skipped[record.Type][sequence] = true
+	}
+}
+
+// A NamespacedId is an ID together with its namespace and its sequence number within the namespace
+type NamespacedId struct {
+	Namespace string `json:"namespace"`
+	Sequence  int64  `json:"sequence"`
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+}
+
+// applyNamespace updates the in-memory state with a namespace record
+func (s *ReservationStore) applyNamespace(record storeRecord) {
+	switch record.Action {
+	case namespaceAction:
+		s.namespaces[record.Namespace] = &NamespaceUsage{Namespace: record.Namespace, Slot: record.Number, CreatedAt: record.Time, Consumed: map[string]int64{}, offsets: s.freeSlots[record.Number], skipped: map[string]map[int64]bool{}}
+		delete(s.freeSlots, record.Number)
+		if record.Number >= s.usedSlots {
+			s.usedSlots = record.Number + 1
+		}
+	case releaseNamespaceAction:
+		usage, ok := s.namespaces[record.Namespace]
+		if !ok {
+			return
+		}
+		used := map[string]int64{}
+		for typeLabel, offset := range usage.offsets {
+			used[typeLabel] = offset
+		}
+		for typeLabel, count := range usage.Consumed {
+			used[typeLabel] += count
+		}
+		s.freeSlots[usage.Slot] = used
+		delete(s.namespaces, record.Namespace)
+	case consumeAction:
+		if usage, ok := s.namespaces[record.Namespace]; ok {
+			usage.skip(record)
+			usage.Consumed[record.Type] = record.Number
+		}
+		for _, id := range record.Ids {
+			s.knownIds[id] = record.Type
+		}
+	}
+}
+
+// namespaceCounter returns the counter of the sequence at which the sequence-th ID of the namespace in the given slot is found
+func namespaceCounter(slot int64, sequence int64) *big.Int {
+	counter := new(big.Int).Mul(big.NewInt(sequence), big.NewInt(maxNamespaces))
+	return counter.Add(counter, big.NewInt(slot))
+}
+
+// ConsumeNamespaceIds returns the next count IDs of the namespace from the generator's sequence. A new namespace is registered on its first use.
+// Sequence numbers whose ID was already handed out otherwise or is on the exclusion list are skipped.
+func (s *ReservationStore) ConsumeNamespaceIds(namespace string, generator *SequenceGenerator, count int) ([]NamespacedId, error) {
+	if !namespacePattern.MatchString(namespace) {
+		return nil, fmt.Errorf("the namespace '%s' does not match the pattern %s", namespace, namespacePattern.String())
+	}
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	var records []storeRecord
+	usage, ok := s.namespaces[namespace]
+	if !ok {
+		slot, slotErr := s.nextFreeSlot()
+		if slotErr != nil {
+			return nil, slotErr
+		}
+		usage = &NamespaceUsage{Namespace: namespace, Slot: slot, Consumed: map[string]int64{}, offsets: s.freeSlots[slot], skipped: map[string]map[int64]bool{}}
+		records = append(records, storeRecord{Action: namespaceAction, Namespace: namespace, Number: usage.Slot, Time: now})
+	}
+	typeLabel := generator.idType.Label
+	next := usage.Consumed[typeLabel]
+	result := make([]NamespacedId, 0, count)
+	ids := make([]string, 0, count)
+	sequences := make([]int64, 0, count)
+	for skipped := 0; len(result) < count; next++ {
+		counter := usage.counter(typeLabel, next)
+		if counter.Cmp(generator.Size()) >= 0 {
+			return nil, fmt.Errorf("the namespace '%s' has consumed all of its %s-IDs", namespace, typeLabel)
+		}
+		id, idErr := generator.IdAt(counter)
+		if idErr != nil {
+			return nil, idErr
+		}
+		if _, known := s.knownIds[id]; known || exclusions.rejects(typeLabel, id) {
+			log.Printf("Skipped the %s '%s' (sequence number %d of namespace '%s') because it's already known or on the exclusion list", typeLabel, id, next, namespace)
+			if skipped++; skipped >= maxGenerationAttempts {
+				return nil, fmt.Errorf("could not find an unused ID within %d attempts", maxGenerationAttempts)
+			}
+			continue
+		}
+		result = append(result, NamespacedId{Namespace: namespace, Sequence: next, Id: id, Type: typeLabel})
+		ids = append(ids, id)
+		sequences = append(sequences, next)
+	}
+	records = append(records, storeRecord{Action: consumeAction, Namespace: namespace, Type: typeLabel, Number: next, Ids: ids, Sequences: sequences, Time: now})
+	if err = s.write(records...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nextFreeSlot returns the lowest slot of a released namespace or, if there is none, a slot that was never used
+func (s *ReservationStore) nextFreeSlot() (int64, error) {
+	if len(s.freeSlots) > 0 {
+		slots := make([]int64, 0, len(s.freeSlots))
+		for slot := range s.freeSlots {
+			slots = append(slots, slot)
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+		return slots[0], nil
+	}
+	if s.usedSlots >= maxNamespaces {
+		return 0, fmt.Errorf("there are already %d namespaces, which is the maximum; release namespaces that are no longer needed", maxNamespaces)
+	}
+	return s.usedSlots, nil
+}
+
+// ReleaseNamespace removes the namespace and frees its slot for a new namespace. The IDs the namespace consumed are never handed out again
+// and can no longer be queried with NamespaceIdAt; if the name is used again, it's a new namespace that starts with sequence number 0.
+func (s *ReservationStore) ReleaseNamespace(namespace string) (NamespaceUsage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	usage, ok := s.namespaces[namespace]
+	if !ok {
+		return NamespaceUsage{}, fmt.Errorf("'%s': %w", namespace, ErrNamespaceNotFound)
+	}
+	released := usage.copy()
+	if err := s.write(storeRecord{Action: releaseNamespaceAction, Namespace: namespace, Time: time.Now().UTC()}); err != nil {
+		return NamespaceUsage{}, err
+	}
+	return released, nil
+}
+
+// NamespaceIdAt returns the ID with the given sequence number of the namespace again. Only sequence numbers the namespace has already handed out can be queried;
+// for skipped ones, ErrSequenceNotIssued is returned.
+func (s *ReservationStore) NamespaceIdAt(namespace string, generator *SequenceGenerator, sequence int64) (NamespacedId, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	usage, ok := s.namespaces[namespace]
+	if !ok {
+		return NamespacedId{}, fmt.Errorf("'%s': %w", namespace, ErrNamespaceNotFound)
+	}
+	typeLabel := generator.idType.Label
+	if sequence < 0 {
+		return NamespacedId{}, fmt.Errorf("the sequence number must not be negative")
+	}
+	if sequence >= usage.Consumed[typeLabel] {
+		return NamespacedId{}, fmt.Errorf("%w: the namespace '%s' has only consumed the %s sequence numbers 0 to %d", ErrSequenceNotIssued, namespace, typeLabel, usage.Consumed[typeLabel]-1)
+	}
+	if usage.skipped[typeLabel][sequence] {
+		return NamespacedId{}, fmt.Errorf("%w: the namespace '%s' skipped the %s sequence number %d because its ID was already handed out otherwise or is excluded", ErrSequenceNotIssued, namespace, typeLabel, sequence)
+	}
+	id, err := generator.IdAt(usage.counter(typeLabel, sequence))
+	if err != nil {
+		return NamespacedId{}, err
+	}
+	return NamespacedId{Namespace: namespace, Sequence: sequence, Id: id, Type: typeLabel}, nil
+}
+
+// Namespaces returns the usage of all namespaces, ordered by name
+func (s *ReservationStore) Namespaces() []NamespaceUsage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := make([]NamespaceUsage, 0, len(s.namespaces))
+	for _, usage := range s.namespaces {
+		result = append(result, usage.copy())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result
+}
+
+// NamespaceRequest is the body of the POST request to /namespaces/:namespace/ids
+type NamespaceRequest struct {
+	Type  string `json:"type,omitempty"`  // Type of the IDs; defaults to the configured ID_TYPE_TO_GENERATE
+	Count int    `json:"count,omitempty"` // Count is the number of IDs; defaults to 1
+}
+
+// getNamespaceGenerator returns the reservation store and the SequenceGenerator for the given type or writes an error response and returns nil.
+// Namespaces always use the server's SEQUENCE_KEY (and ignore the X-Sequence-Key header): the store only records the sequence numbers a namespace consumed, so they must map to the same IDs in every request.
+func getNamespaceGenerator(c *gin.Context, typeName string) (*ReservationStore, *SequenceGenerator) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return nil, nil
+	}
+	key, err := getServerSequenceKey()
+	if err != nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return nil, nil
+	}
+	idType, err := getIdTypeOrConfiguredFor(c, typeName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil
+	}
+	generator, err := NewSequenceGenerator(key, idType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil
+	}
+	return store, generator
+}
+
+// consumeNamespaceIdsHandler returns the next IDs of the namespace from the path
+func consumeNamespaceIdsHandler(c *gin.Context) {
+	var request NamespaceRequest
+	if err := bindOptionalJson(c, &request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Count == 0 {
+		request.Count = 1
+	}
+	if request.Count < 0 || request.Count > maxSequenceCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the 'count' has to be between 1 and %d", maxSequenceCount)})
+		return
+	}
+	store, generator := getNamespaceGenerator(c, request.Type)
+	if store == nil {
+		return
+	}
+	ids, err := store.ConsumeNamespaceIds(c.Param("namespace"), generator, request.Count)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, ids)
+}
+
+// namespaceIdHandler returns the ID with the sequence number from the path of the namespace from the path again
+func namespaceIdHandler(c *gin.Context) {
+	sequence, err := strconv.ParseInt(c.Param("sequence"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid sequence number '%s'", c.Param("sequence"))})
+		return
+	}
+	store, generator := getNamespaceGenerator(c, c.Query("type"))
+	if store == nil {
+		return
+	}
+	namespacedId, err := store.NamespaceIdAt(c.Param("namespace"), generator, sequence)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, namespacedId)
+}
+
+// releaseNamespaceHandler releases the namespace from the path
+func releaseNamespaceHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	usage, err := store.ReleaseNamespace(c.Param("namespace"))
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// listNamespacesHandler returns how many IDs of each type each namespace has consumed
+func listNamespacesHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	c.JSON(http.StatusOK, store.Namespaces())
+}
+
+// generateNamespacedIdJson is used by /json if a ?namespace= is given: instead of a random ID, it returns the next ID of the namespace
+func generateNamespacedIdJson(c *gin.Context, namespace string) {
+	store, generator := getNamespaceGenerator(c, c.Query("type"))
+	if store == nil {
+		return
+	}
+	ids, err := store.ConsumeNamespaceIds(namespace, generator, 1)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ids[0])
+}
@@ -0,0 +1,176 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+)
+
+func (s *Suite) Test_Namespaces_Never_Overlap_And_Are_Reproducible() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	generator, _ := idgenerator.NewSequenceGenerator([]byte("shared key"), idgenerator.MaLoIdType)
+	teamA, err := store.ConsumeNamespaceIds("team-a", generator, 200)
+	then.AssertThat(s.T(), err, is.Nil())
+	teamB, err := store.ConsumeNamespaceIds("ci-job-4711", generator, 200)
+	then.AssertThat(s.T(), err, is.Nil())
+	seen := map[string]bool{}
+	for _, namespacedId := range append(teamA, teamB...) {
+		then.AssertThat(s.T(), seen[namespacedId.Id], is.False())
+		seen[namespacedId.Id] = true
+	}
+	then.AssertThat(s.T(), teamA[199].Sequence, is.EqualTo(int64(199)))
+	// the random generators never return an ID that was consumed by a namespace
+	isNew, err := store.Issue(idgenerator.MaLoIdType, teamB[0].Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), isNew, is.False())
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	again, err := store.NamespaceIdAt("team-a", generator, 42)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), again, is.EqualTo(teamA[42]))
+	next, err := store.ConsumeNamespaceIds("team-a", generator, 1)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), next[0].Sequence, is.EqualTo(int64(200)))
+	_, err = store.NamespaceIdAt("team-a", generator, 201)
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	usages := store.Namespaces()
+	then.AssertThat(s.T(), len(usages), is.EqualTo(2))
+	then.AssertThat(s.T(), usages[1].Namespace, is.EqualTo("team-a"))
+	then.AssertThat(s.T(), usages[1].Consumed["MaLo"], is.EqualTo(int64(201)))
+	_, err = store.ConsumeNamespaceIds("team a/../b", generator, 1)
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Skipped_Sequence_Numbers_Are_Not_Returned() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	generator, _ := idgenerator.NewSequenceGenerator([]byte("shared key"), idgenerator.MaLoIdType)
+	// the ID of sequence number 1 of the first namespace (slot 0) is handed out by the random generator before
+	knownId, _ := generator.IdAt(big.NewInt(1 * 1024))
+	_, err = store.Issue(idgenerator.MaLoIdType, knownId)
+	then.AssertThat(s.T(), err, is.Nil())
+	consumed, err := store.ConsumeNamespaceIds("team-a", generator, 2)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), consumed[0].Sequence, is.EqualTo(int64(0)))
+	then.AssertThat(s.T(), consumed[1].Sequence, is.EqualTo(int64(2)))
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	_, err = store.NamespaceIdAt("team-a", generator, 1)
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrSequenceNotIssued), is.True())
+	again, err := store.NamespaceIdAt("team-a", generator, 2)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), again, is.EqualTo(consumed[1]))
+	_, err = store.NamespaceIdAt("team-a", generator, 3)
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrSequenceNotIssued), is.True())
+}
+
+func (s *Suite) Test_Released_Namespaces_Free_Their_Slot() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	generator, _ := idgenerator.NewSequenceGenerator([]byte("shared key"), idgenerator.MaLoIdType)
+	ciJob, err := store.ConsumeNamespaceIds("ci-job-1", generator, 50)
+	then.AssertThat(s.T(), err, is.Nil())
+	released, err := store.ReleaseNamespace("ci-job-1")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), released.Consumed["MaLo"], is.EqualTo(int64(50)))
+	_, err = store.ReleaseNamespace("ci-job-1")
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrNamespaceNotFound), is.True())
+	_, err = store.NamespaceIdAt("ci-job-1", generator, 0)
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrNamespaceNotFound), is.True())
+
+	// the next namespace reuses the slot but continues after the sequence numbers of the released one
+	nextJob, err := store.ConsumeNamespaceIds("ci-job-2", generator, 50)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), nextJob[0].Sequence, is.EqualTo(int64(0)))
+	seen := map[string]bool{}
+	for _, namespacedId := range append(ciJob, nextJob...) {
+		then.AssertThat(s.T(), seen[namespacedId.Id], is.False())
+		seen[namespacedId.Id] = true
+	}
+	usages := store.Namespaces()
+	then.AssertThat(s.T(), len(usages), is.EqualTo(1))
+	then.AssertThat(s.T(), usages[0].Slot, is.EqualTo(int64(0)))
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	again, err := store.NamespaceIdAt("ci-job-2", generator, 10)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), again, is.EqualTo(nextJob[10]))
+	_, err = store.ConsumeNamespaceIds("ci-job-3", generator, 1)
+	then.AssertThat(s.T(), err, is.Nil())
+	usages = store.Namespaces()
+	then.AssertThat(s.T(), usages[1].Namespace, is.EqualTo("ci-job-3"))
+	then.AssertThat(s.T(), usages[1].Slot, is.EqualTo(int64(1)))
+}
+
+func (s *Suite) Test_Namespace_Endpoints() {
+	s.T().Setenv("RESERVATION_STORE_PATH", filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	s.T().Setenv("SEQUENCE_KEY", "shared key")
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "NELO")
+	router := idgenerator.NewRouter()
+	response := performRequest(router, "POST", "/namespaces/team-a/ids", strings.NewReader(`{"count":2,"type":"SRID"}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusCreated))
+	var ids []idgenerator.NamespacedId
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &ids), is.Nil())
+	then.AssertThat(s.T(), len(ids), is.EqualTo(2))
+	then.AssertThat(s.T(), ids[1].Type, is.EqualTo("SR"))
+
+	response = performGetRequest(router, "/json?namespace=team-a")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var namespacedId idgenerator.NamespacedId
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &namespacedId), is.Nil())
+	then.AssertThat(s.T(), namespacedId.Type, is.EqualTo("NeLo"))
+	then.AssertThat(s.T(), namespacedId.Sequence, is.EqualTo(int64(0)))
+
+	response = performGetRequest(router, "/namespaces/team-a/ids/1?type=SRID")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &namespacedId), is.Nil())
+	then.AssertThat(s.T(), namespacedId, is.EqualTo(ids[1]))
+	// the X-Sequence-Key header is ignored, so a caller can't consume the sequence numbers of a namespace with another key
+	request, _ := http.NewRequest("GET", "/namespaces/team-a/ids/1?type=SRID", nil)
+	request.Header.Set("X-Sequence-Key", "other key")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	then.AssertThat(s.T(), recorder.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), json.Unmarshal(recorder.Body.Bytes(), &namespacedId), is.Nil())
+	then.AssertThat(s.T(), namespacedId, is.EqualTo(ids[1]))
+	response = performGetRequest(router, "/namespaces/team-b/ids/0")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+	response = performGetRequest(router, "/namespaces/team-a/ids/2?type=SRID")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+	response = performGetRequest(router, "/namespaces/team-a/ids/-1?type=SRID")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+
+	response = performGetRequest(router, "/namespaces")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var usages []idgenerator.NamespaceUsage
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &usages), is.Nil())
+	then.AssertThat(s.T(), len(usages), is.EqualTo(1))
+	then.AssertThat(s.T(), usages[0].Consumed, is.EqualTo(map[string]int64{"SR": 2, "NeLo": 1}))
+
+	response = performRequest(router, "POST", "/namespaces/team-a/release", nil)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	response = performRequest(router, "POST", "/namespaces/team-a/release", nil)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+	response = performGetRequest(router, "/namespaces/team-a/ids/1?type=SRID")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+}
@@ -0,0 +1,196 @@
+package idgenerator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// pseudonymizationKeyHeader is the HTTP header in which callers may provide their own pseudonymization key.
+// De-pseudonymization always requires this header, so that only those who know the key can reverse pseudonyms.
+const pseudonymizationKeyHeader = "X-Pseudonymization-Key"
+
+// A Pseudonymizer replaces real IDs with fake but valid IDs of the same type. The same real ID (and key) always results in the same pseudonym.
+type Pseudonymizer struct {
+	key []byte
+	// Both modes use a keyed permutation of the ID space, so distinct valid IDs never get the same pseudonym.
+	// Non-reversible pseudonyms use a permutation with a key that is derived from the secret key; Depseudonymize is not offered for them.
+	reversible bool
+}
+
+// NewPseudonymizer returns a Pseudonymizer that uses the given secret key
+func NewPseudonymizer(key []byte, reversible bool) (*Pseudonymizer, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the pseudonymization key must not be empty")
+	}
+	return &Pseudonymizer{key: key, reversible: reversible}, nil
+}
+
+// permutation returns the keyed permutation of the ID space of the type. Non-reversible pseudonyms use a separate key, so they differ from the reversible ones.
+func (p *Pseudonymizer) permutation(idType IdType) (keyedPermutation, error) {
+	key := p.key
+	if !p.reversible {
+		mac := hmac.New(sha256.New, p.key)
+		mac.Write([]byte("non-reversible"))
+		key = mac.Sum(nil)
+	}
+	return newKeyedPermutation(key, "pseudonymization:"+idType.Name, idType.SpaceSize())
+}
+
+// Pseudonymize returns the pseudonym of the given id. The id has to match the structure of its type but its checksum may be wrong; the pseudonym always has a valid checksum.
+// The checksum is not part of the mapping, so IDs that only differ in their checksum get the same pseudonym.
+func (p *Pseudonymizer) Pseudonymize(idType IdType, id string) (string, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	index, err := idType.IndexOf(id)
+	if err != nil {
+		return "", err
+	}
+	permutation, err := p.permutation(idType)
+	if err != nil {
+		return "", err
+	}
+	pseudonymIndex, err := permutation.Permute(index)
+	if err != nil {
+		return "", err
+	}
+	return idType.IdAt(pseudonymIndex)
+}
+
+// Depseudonymize returns the original ID of a pseudonym that was created in reversible mode with the same key.
+// Note that the result always has a valid checksum, even if the original ID had a wrong one.
+func (p *Pseudonymizer) Depseudonymize(idType IdType, pseudonym string) (string, error) {
+	if !p.reversible {
+		return "", fmt.Errorf("only pseudonyms that were created in reversible mode can be de-pseudonymized")
+	}
+	pseudonym = strings.ToUpper(strings.TrimSpace(pseudonym))
+	if err := idType.Validate(pseudonym); err != nil {
+		return "", fmt.Errorf("'%s' is no pseudonym: %w", pseudonym, err)
+	}
+	index, err := idType.IndexOf(pseudonym)
+	if err != nil {
+		return "", err
+	}
+	permutation, err := p.permutation(idType)
+	if err != nil {
+		return "", err
+	}
+	originalIndex, err := permutation.Invert(index)
+	if err != nil {
+		return "", err
+	}
+	return idType.IdAt(originalIndex)
+}
+
+// PseudonymizationRequest is the body of the POST requests to /pseudonymize and /depseudonymize
+type PseudonymizationRequest struct {
+	Ids        []string `json:"ids" binding:"required"`
+	Type       string   `json:"type,omitempty"`       // Type is optional; if it's not set, the type of each ID is detected separately
+	Reversible bool     `json:"reversible,omitempty"` // Reversible is ignored by /depseudonymize, which is always reversible
+}
+
+// A Pseudonym maps a real ID to its pseudonym
+type Pseudonym struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	Pseudonym string `json:"pseudonym"`
+}
+
+// getPseudonymizationKey returns the key from the request header or, if allowed and the header is not set, from the environment variable PSEUDONYMIZATION_KEY
+func getPseudonymizationKey(c *gin.Context, allowServerKey bool) ([]byte, error) {
+	if key := c.GetHeader(pseudonymizationKeyHeader); key != "" {
+		return []byte(key), nil
+	}
+	if !allowServerKey {
+		return nil, fmt.Errorf("the key has to be provided in the '%s' header", pseudonymizationKeyHeader)
+	}
+	if key, ok := os.LookupEnv("PSEUDONYMIZATION_KEY"); ok && key != "" {
+		return []byte(key), nil
+	}
+	return nil, fmt.Errorf("neither the '%s' header nor the environment variable 'PSEUDONYMIZATION_KEY' is set", pseudonymizationKeyHeader)
+}
+
+// mapIds applies mapping to each of the ids and returns the results. If typeName is empty, the type of each ID is detected separately.
+func mapIds(ids []string, typeName string, mapping func(IdType, string) (string, error)) ([]Pseudonym, error) {
+	var requestedType *IdType
+	if typeName != "" {
+		idType, err := getIdType(typeName)
+		if err != nil {
+			return nil, err
+		}
+		requestedType = &idType
+	}
+	result := make([]Pseudonym, 0, len(ids))
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		idType, ok := IdType{}, true
+		if requestedType != nil {
+			idType = *requestedType
+		} else if idType, ok = detectIdType(id); !ok {
+			return nil, fmt.Errorf("could not detect the type of '%s'; please provide the 'type'", id)
+		}
+		mapped, err := mapping(idType, id)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Pseudonym{Id: id, Type: idType.Label, Pseudonym: mapped})
+	}
+	return result, nil
+}
+
+// pseudonymizationHandler returns the pseudonyms of the IDs in the request body
+func pseudonymizationHandler(c *gin.Context) {
+	var request PseudonymizationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := getPseudonymizationKey(c, true)
+	if err != nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	pseudonymizer, err := NewPseudonymizer(key, request.Reversible)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pseudonyms, err := mapIds(request.Ids, request.Type, pseudonymizer.Pseudonymize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pseudonyms)
+}
+
+// depseudonymizationHandler returns the original IDs of the (reversible) pseudonyms in the request body. The key has to be provided in the X-Pseudonymization-Key header.
+func depseudonymizationHandler(c *gin.Context) {
+	var request PseudonymizationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := getPseudonymizationKey(c, false)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	pseudonymizer, err := NewPseudonymizer(key, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	originals, err := mapIds(request.Ids, request.Type, pseudonymizer.Depseudonymize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// the mapping was applied in the opposite direction (from pseudonym to ID), so we swap them to return the same structure as /pseudonymize
+	for i := range originals {
+		originals[i].Id, originals[i].Pseudonym = originals[i].Pseudonym, originals[i].Id
+	}
+	c.JSON(http.StatusOK, originals)
+}
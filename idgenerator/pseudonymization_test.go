@@ -0,0 +1,137 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var allIdTypes = []idgenerator.IdType{idgenerator.MaLoIdType, idgenerator.NeLoIdType, idgenerator.MeLoIdType, idgenerator.TRIdType, idgenerator.SRIdType}
+
+func (s *Suite) Test_Id_Space_Round_Trip() {
+	for _, idType := range allIdTypes {
+		for i := 0; i < 20; i++ {
+			id, err := idType.NewRandomId()
+			then.AssertThat(s.T(), err, is.Nil())
+			index, err := idType.IndexOf(id)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), index.Cmp(idType.SpaceSize()) < 0, is.True())
+			idAtIndex, err := idType.IdAt(index)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), idAtIndex, is.EqualTo(id))
+		}
+	}
+	first, err := idgenerator.MaLoIdType.IdAt(big.NewInt(0))
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), first[:10], is.EqualTo("1000000000"))
+	_, err = idgenerator.MaLoIdType.IdAt(idgenerator.MaLoIdType.SpaceSize())
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Pseudonymization_Is_Deterministic_And_Type_Preserving() {
+	for _, reversible := range []bool{false, true} {
+		pseudonymizer, err := idgenerator.NewPseudonymizer([]byte("secret"), reversible)
+		then.AssertThat(s.T(), err, is.Nil())
+		otherPseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("other secret"), reversible)
+		for _, idType := range allIdTypes {
+			id, _ := idType.NewRandomId()
+			pseudonym, err := pseudonymizer.Pseudonymize(idType, id)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), idType.Validate(pseudonym), is.Nil())
+			then.AssertThat(s.T(), pseudonym == id, is.False())
+			again, _ := pseudonymizer.Pseudonymize(idType, id)
+			then.AssertThat(s.T(), again, is.EqualTo(pseudonym))
+			withOtherKey, _ := otherPseudonymizer.Pseudonymize(idType, id)
+			then.AssertThat(s.T(), withOtherKey == pseudonym, is.False())
+		}
+	}
+}
+
+func (s *Suite) Test_Reversible_Pseudonymization() {
+	pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), true)
+	for _, idType := range allIdTypes {
+		for i := 0; i < 20; i++ {
+			id, _ := idType.NewRandomId()
+			pseudonym, err := pseudonymizer.Pseudonymize(idType, id)
+			then.AssertThat(s.T(), err, is.Nil())
+			original, err := pseudonymizer.Depseudonymize(idType, pseudonym)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), original, is.EqualTo(id))
+		}
+	}
+	oneWayPseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), false)
+	_, err := oneWayPseudonymizer.Depseudonymize(idgenerator.MaLoIdType, "41373559241")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Pseudonymization_Is_Unique_And_Reversible() {
+	for _, reversible := range []bool{false, true} {
+		pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), reversible)
+		pseudonyms := map[string]string{}
+		for i := int64(0); i < 1000; i++ {
+			// consecutive MaLo-IDs, starting with 10000851428 (which collided with 10000881459 when the non-reversible mode was based on an HMAC)
+			id, _ := idgenerator.MaLoIdType.IdAt(big.NewInt(85142 + i))
+			pseudonym, err := pseudonymizer.Pseudonymize(idgenerator.MaLoIdType, id)
+			then.AssertThat(s.T(), err, is.Nil())
+			then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(pseudonym), is.Nil())
+			_, collides := pseudonyms[pseudonym]
+			then.AssertThat(s.T(), collides, is.False())
+			pseudonyms[pseudonym] = id
+			if reversible {
+				original, depseudonymizationErr := pseudonymizer.Depseudonymize(idgenerator.MaLoIdType, pseudonym)
+				then.AssertThat(s.T(), depseudonymizationErr, is.Nil())
+				then.AssertThat(s.T(), original, is.EqualTo(id))
+			}
+		}
+	}
+	// the checksum is not part of the mapping, so an ID with a wrong checksum gets the pseudonym of the valid ID
+	pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), false)
+	valid, _ := pseudonymizer.Pseudonymize(idgenerator.MaLoIdType, "41373559241")
+	wrongChecksum, _ := pseudonymizer.Pseudonymize(idgenerator.MaLoIdType, "41373559240")
+	then.AssertThat(s.T(), wrongChecksum, is.EqualTo(valid))
+}
+
+func (s *Suite) Test_Pseudonymization_Endpoints() {
+	err := os.Setenv("PSEUDONYMIZATION_KEY", "server secret")
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = os.Unsetenv("PSEUDONYMIZATION_KEY") }()
+	router := idgenerator.NewRouter()
+	body, _ := json.Marshal(idgenerator.PseudonymizationRequest{Ids: []string{"41373559241", "E1137355921", "41373559241"}, Reversible: true})
+	response := performRequest(router, "POST", "/pseudonymize", bytes.NewReader(body))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var pseudonyms []idgenerator.Pseudonym
+	err = json.NewDecoder(response.Body).Decode(&pseudonyms)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(pseudonyms), is.EqualTo(3))
+	then.AssertThat(s.T(), pseudonyms[0].Type, is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), pseudonyms[1].Type, is.EqualTo("NeLo"))
+	then.AssertThat(s.T(), pseudonyms[2].Pseudonym, is.EqualTo(pseudonyms[0].Pseudonym))
+
+	// de-pseudonymization requires the key in the header, the server key is not used
+	body, _ = json.Marshal(idgenerator.PseudonymizationRequest{Ids: []string{pseudonyms[0].Pseudonym}})
+	response = performRequest(router, "POST", "/depseudonymize", bytes.NewReader(body))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusUnauthorized))
+
+	req, _ := http.NewRequest("POST", "/depseudonymize", bytes.NewReader(body))
+	req.Header.Set("X-Pseudonymization-Key", "server secret")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	then.AssertThat(s.T(), recorder.Code, is.EqualTo(http.StatusOK))
+	var originals []idgenerator.Pseudonym
+	err = json.NewDecoder(recorder.Body).Decode(&originals)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), originals[0].Id, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), originals[0].Pseudonym, is.EqualTo(pseudonyms[0].Pseudonym))
+}
+
+func (s *Suite) Test_Pseudonymization_Without_Key() {
+	body, _ := json.Marshal(idgenerator.PseudonymizationRequest{Ids: []string{"41373559241"}})
+	response := performRequest(idgenerator.NewRouter(), "POST", "/pseudonymize", bytes.NewReader(body))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotImplemented))
+}
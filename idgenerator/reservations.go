@@ -0,0 +1,482 @@
+package idgenerator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reservationStorePathVariable is the environment variable that enables the reservation store. Its value is the path of the store file (which is created if it does not exist).
+const reservationStorePathVariable = "RESERVATION_STORE_PATH"
+
+// maxGenerationAttempts is the number of random IDs the generators try before they give up to find one that has not been handed out yet
+const maxGenerationAttempts = 100
+
+// maxReservationCount is the maximum number of fresh IDs that can be reserved with a single request
+const maxReservationCount = 1000
+
+// ErrIdAlreadyReserved is returned if an ID that is actively reserved by another owner is reserved again
+var ErrIdAlreadyReserved = errors.New("the ID is already reserved")
+
+// ErrIdNotReserved is returned if an ID that is not (or no longer) reserved is released
+var ErrIdNotReserved = errors.New("the ID is not reserved")
+
+// errReservationStoreWrite wraps the I/O errors of the store file, which are no fault of the client
+var errReservationStoreWrite = errors.New("could not write to the reservation store")
+
+// the actions that are recorded in the store file
+const (
+	issueAction   = "issue"
+	reserveAction = "reserve"
+	releaseAction = "release"
+	// the lease actions refer to a whole lease (and all of its IDs) instead of a single ID
+	leaseAction        = "lease"
+	renewAction        = "renew"
+	releaseLeaseAction = "release-lease"
+	// the namespace actions register a namespace, record how many IDs of a type it consumed and release it (which frees its slot)
+	namespaceAction        = "namespace"
+	consumeAction          = "consume"
+	releaseNamespaceAction = "release-namespace"
+)
+
+// storeRecord is a single line of the store file. The file is an append-only log of JSON lines that is replayed when the store is opened.
+type storeRecord struct {
+	Action string    `json:"action"`
+	Id     string    `json:"id"`
+	Type   string    `json:"type,omitempty"`
+	Owner  string    `json:"owner,omitempty"`
+	Time   time.Time `json:"time"`
+	// the following fields are only used by the lease and namespace actions
+	Lease     string     `json:"lease,omitempty"`
+	Ids       []string   `json:"ids,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Namespace string     `json:"namespace,omitempty"`
+	Number    int64      `json:"number,omitempty"`    // Number is the slot of a new namespace or the next sequence number after a consumption
+	Sequences []int64    `json:"sequences,omitempty"` // Sequences are the sequence numbers of the Ids of a consumption (the others were skipped)
+}
+
+// A Reservation is an ID that was claimed by an owner (e.g. a team or a test run)
+type Reservation struct {
+	Id         string     `json:"id"`
+	Type       string     `json:"type"`
+	Owner      string     `json:"owner"`
+	ReservedAt time.Time  `json:"reservedAt"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"` // ReleasedAt is only set for reservations that were released
+	Lease      string     `json:"lease,omitempty"`      // Lease is the ID of the lease, if the ID was reserved as part of one
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`  // ExpiresAt is only set for reservations that are part of a lease
+}
+
+// isActive returns true if the reservation was neither released nor has it expired at the given time
+func (r *Reservation) isActive(now time.Time) bool {
+	return r.ReleasedAt == nil && (r.ExpiresAt == nil || r.ExpiresAt.After(now))
+}
+
+// A ReservationStore records every ID that was handed out (by the generators or as a reservation) in a local file, so that no ID is handed out twice, even across restarts.
+// It's safe for concurrent use within one process; several processes must not share the same file.
+type ReservationStore struct {
+	mutex        sync.Mutex
+	file         *os.File
+	knownIds     map[string]string // knownIds maps every ID that was ever handed out to its type
+	reservations map[string]*Reservation
+	leases       map[string]*Lease
+	namespaces   map[string]*NamespaceUsage
+	freeSlots    map[int64]map[string]int64 // freeSlots maps the slots of released namespaces to the sequence numbers that were already used in them (per type)
+	usedSlots    int64                      // usedSlots is the number of slots that were ever assigned to a namespace
+}
+
+// OpenReservationStore opens (or creates) the store file at the given path and loads all IDs recorded in it
+func OpenReservationStore(path string) (*ReservationStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the reservation store: %w", err)
+	}
+	store := &ReservationStore{file: file, knownIds: map[string]string{}, reservations: map[string]*Reservation{}, leases: map[string]*Lease{}, namespaces: map[string]*NamespaceUsage{}, freeSlots: map[int64]map[string]int64{}}
+	if err = store.load(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("could not read the reservation store '%s': %w", path, err)
+	}
+	return store, nil
+}
+
+// load replays all records of the store file
+func (s *ReservationStore) load() error {
+	scanner := bufio.NewScanner(s.file)
+	validLength := int64(0) // validLength is the number of bytes of all complete records (including their line breaks)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Bytes()
+		var record storeRecord
+		if err := json.Unmarshal(line, &record); len(line) > 0 && err != nil {
+			if scanner.Scan() {
+				return fmt.Errorf("line %d is corrupt: %w", lineNumber, err)
+			}
+			// the last line may be incomplete if the process crashed while writing it; it's removed so that new records are not appended to it
+			return s.file.Truncate(validLength)
+		}
+		if len(line) > 0 {
+			s.apply(record)
+		}
+		validLength += int64(len(line)) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if info, err := s.file.Stat(); err != nil {
+		return err
+	} else if info.Size() < validLength {
+		// the last record is complete but its line break is missing
+		_, err = s.file.Write([]byte("\n"))
+		return err
+	}
+	return nil
+}
+
+// apply updates the in-memory state with a record (that is either read from or about to be written to the store file)
+func (s *ReservationStore) apply(record storeRecord) {
+	switch record.Action {
+	case issueAction:
+		s.knownIds[record.Id] = record.Type
+	case reserveAction:
+		s.knownIds[record.Id] = record.Type
+		s.reservations[record.Id] = &Reservation{Id: record.Id, Type: record.Type, Owner: record.Owner, ReservedAt: record.Time}
+	case releaseAction:
+		if reservation, ok := s.reservations[record.Id]; ok {
+			releasedAt := record.Time
+			reservation.ReleasedAt = &releasedAt
+		}
+	case namespaceAction, consumeAction, releaseNamespaceAction:
+		s.applyNamespace(record)
+	default:
+		s.applyLease(record)
+	}
+}
+
+// write appends the records to the store file (in a single write, so that they are either all persisted or none) and applies them
+func (s *ReservationStore) write(records ...storeRecord) error {
+	var lines []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if _, err := s.file.Write(lines); err != nil {
+		return fmt.Errorf("%w: %w", errReservationStoreWrite, err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", errReservationStoreWrite, err)
+	}
+	for _, record := range records {
+		s.apply(record)
+	}
+	return nil
+}
+
+// Close closes the store file
+func (s *ReservationStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
+
+// Issue records that the id was handed out. It returns false (and records nothing) if the id was already handed out before.
+func (s *ReservationStore) Issue(idType IdType, id string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, known := s.knownIds[id]; known {
+		return false, nil
+	}
+	return true, s.write(storeRecord{Action: issueAction, Id: id, Type: idType.Label, Time: time.Now().UTC()})
+}
+
+// IssueAll records that the ids were handed out, like Issue but with a single write for all of them. It returns for each id whether it's new;
+// ids that were already handed out before are not recorded again.
+func (s *ReservationStore) IssueAll(idType IdType, ids []string) ([]bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	isNew := make([]bool, len(ids))
+	records := make([]storeRecord, 0, len(ids))
+	for i, id := range ids {
+		if _, known := s.knownIds[id]; known {
+			continue
+		}
+		isNew[i] = true
+		records = append(records, storeRecord{Action: issueAction, Id: id, Type: idType.Label, Time: now})
+	}
+	if len(records) == 0 {
+		return isNew, nil
+	}
+	return isNew, s.write(records...)
+}
+
+// IsKnown returns true if the id was already handed out (or reserved)
+func (s *ReservationStore) IsKnown(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, known := s.knownIds[strings.ToUpper(strings.TrimSpace(id))]
+	return known
+}
+
+// ReserveNew generates count IDs of the given type that were never handed out before and reserves them for the owner
+func (s *ReservationStore) ReserveNew(idType IdType, count int, owner string) ([]Reservation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	records := make([]storeRecord, 0, count)
+	newIds := map[string]bool{}
+	for len(records) < count {
+		id, err := s.generateUnknownId(idType, newIds)
+		if err != nil {
+			return nil, err
+		}
+		newIds[id] = true
+		records = append(records, storeRecord{Action: reserveAction, Id: id, Type: idType.Label, Owner: owner, Time: now})
+	}
+	if err := s.write(records...); err != nil {
+		return nil, err
+	}
+	return s.collectReservations(records), nil
+}
+
+// generateUnknownId returns a random ID of the given type that is neither known to the store nor already chosen nor on the exclusion list
+func (s *ReservationStore) generateUnknownId(idType IdType, alreadyChosen map[string]bool) (string, error) {
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return "", err
+	}
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		id, generationErr := idType.newUncheckedId() // the exclusion list is checked below
+		if generationErr != nil {
+			return "", generationErr
+		}
+		if _, known := s.knownIds[id]; !known && !alreadyChosen[id] && !exclusions.rejects(idType.Label, id) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("could not find an unused %s-ID within %d attempts", idType.Label, maxGenerationAttempts)
+}
+
+// Reserve reserves the given (valid) IDs for the owner. IDs that were handed out by the generators or released before may be reserved, but none of the IDs may be actively reserved by another owner.
+// Either all IDs are reserved or none.
+func (s *ReservationStore) Reserve(ids []string, owner string) ([]Reservation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	records := make([]storeRecord, 0, len(ids))
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		idType, ok := detectIdType(id)
+		if !ok {
+			return nil, fmt.Errorf("could not detect the type of '%s'", id)
+		}
+		if err := idType.Validate(id); err != nil {
+			return nil, err
+		}
+		if reservation, ok := s.reservations[id]; ok && reservation.isActive(now) && reservation.Owner != owner {
+			return nil, fmt.Errorf("'%s' is reserved by '%s': %w", id, reservation.Owner, ErrIdAlreadyReserved)
+		}
+		records = append(records, storeRecord{Action: reserveAction, Id: id, Type: idType.Label, Owner: owner, Time: now})
+	}
+	if err := s.write(records...); err != nil {
+		return nil, err
+	}
+	return s.collectReservations(records), nil
+}
+
+// Release releases the reservations of the given IDs, which all have to be reserved by the owner.
+// The released IDs are still never handed out by the generators again. Either all IDs are released or none.
+func (s *ReservationStore) Release(ids []string, owner string) ([]Reservation, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("the owner of the reservations is required")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	records := make([]storeRecord, 0, len(ids))
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		reservation, ok := s.reservations[id]
+		if !ok || !reservation.isActive(now) {
+			return nil, fmt.Errorf("'%s': %w", id, ErrIdNotReserved)
+		}
+		if reservation.Owner != owner {
+			return nil, fmt.Errorf("'%s' is reserved by '%s': %w", id, reservation.Owner, ErrIdAlreadyReserved)
+		}
+		records = append(records, storeRecord{Action: releaseAction, Id: id, Time: now})
+	}
+	if err := s.write(records...); err != nil {
+		return nil, err
+	}
+	return s.collectReservations(records), nil
+}
+
+// collectReservations returns copies of the current reservations of the IDs in the records
+func (s *ReservationStore) collectReservations(records []storeRecord) []Reservation {
+	result := make([]Reservation, 0, len(records))
+	for _, record := range records {
+		result = append(result, *s.reservations[record.Id])
+	}
+	return result
+}
+
+// Reservations returns the active reservations (including those of leases) (of the given owner, if not empty), ordered by their ID
+func (s *ReservationStore) Reservations(owner string) []Reservation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	result := []Reservation{}
+	for _, reservation := range s.reservations {
+		if reservation.isActive(now) && (owner == "" || reservation.Owner == owner) {
+			result = append(result, *reservation)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+var (
+	reservationStoresMutex sync.Mutex
+	// reservationStores contains the stores that were already opened, by their path, so that each file is only opened once per process
+	reservationStores = map[string]*ReservationStore{}
+)
+
+// getReservationStore returns the store configured in the RESERVATION_STORE_PATH environment variable or nil if the variable is not set
+func getReservationStore() (*ReservationStore, error) {
+	path, ok := os.LookupEnv(reservationStorePathVariable)
+	if !ok || path == "" {
+		return nil, nil
+	}
+	reservationStoresMutex.Lock()
+	defer reservationStoresMutex.Unlock()
+	if store, ok := reservationStores[path]; ok {
+		return store, nil
+	}
+	store, err := OpenReservationStore(path)
+	if err != nil {
+		return nil, err
+	}
+	reservationStores[path] = store
+	return store, nil
+}
+
+// ReservationRequest is the body of the POST requests to /reservations and /reservations/release
+type ReservationRequest struct {
+	Owner string   `json:"owner"`           // Owner is required; only reservations of this owner can be released
+	Type  string   `json:"type,omitempty"`  // Type of the fresh IDs to reserve (only used with Count)
+	Count int      `json:"count,omitempty"` // Count is the number of fresh IDs to reserve
+	Ids   []string `json:"ids,omitempty"`   // Ids are specific IDs to reserve or release
+}
+
+// getReservationStoreOrAbort returns the configured store or writes an error response and returns nil
+func getReservationStoreOrAbort(c *gin.Context) *ReservationStore {
+	store, err := getReservationStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil
+	}
+	if store == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": fmt.Sprintf("no value set for environment variable '%s'; the reservation store is disabled", reservationStorePathVariable)})
+		return nil
+	}
+	return store
+}
+
+// reservationErrorStatus returns the HTTP status code for an error of the reservation store
+func reservationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrIdAlreadyReserved):
+		return http.StatusConflict
+	case errors.Is(err, ErrIdNotReserved), errors.Is(err, ErrLeaseNotFound), errors.Is(err, ErrNamespaceNotFound), errors.Is(err, ErrSequenceNotIssued):
+		return http.StatusNotFound
+	case errors.Is(err, ErrLeaseExpired):
+		return http.StatusGone
+	case errors.Is(err, errReservationStoreWrite):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// listReservationsHandler returns the active reservations; use ?owner= to only list those of one owner
+func listReservationsHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	c.JSON(http.StatusOK, store.Reservations(c.Query("owner")))
+}
+
+// reservationHandler reserves either count fresh IDs of the given type or the given ids for the owner
+func reservationHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	var request ReservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Owner == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the 'owner' of the reservation is required"})
+		return
+	}
+	var reservations []Reservation
+	var err error
+	switch {
+	case len(request.Ids) > 0 && request.Count > 0:
+		err = fmt.Errorf("either 'ids' or 'count' may be set, not both")
+	case len(request.Ids) > 0:
+		reservations, err = store.Reserve(request.Ids, request.Owner)
+	case request.Count > 0 && request.Count <= maxReservationCount:
+		idType, typeErr := getIdType(request.Type)
+		if typeErr != nil {
+			err = typeErr
+			break
+		}
+		reservations, err = store.ReserveNew(idType, request.Count, request.Owner)
+	default:
+		err = fmt.Errorf("either 'ids' or a 'count' between 1 and %d is required", maxReservationCount)
+	}
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, reservations)
+}
+
+// releaseHandler releases the reservations of the given ids
+func releaseHandler(c *gin.Context) {
+	store := getReservationStoreOrAbort(c)
+	if store == nil {
+		return
+	}
+	var request ReservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the 'ids' to release are required"})
+		return
+	}
+	if request.Owner == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the 'owner' of the reservations is required"})
+		return
+	}
+	released, err := store.Release(request.Ids, request.Owner)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, released)
+}
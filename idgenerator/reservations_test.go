@@ -0,0 +1,106 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (s *Suite) Test_Reservation_Store_Survives_Restarts() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	reservations, err := store.ReserveNew(idgenerator.NeLoIdType, 3, "team-a")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(reservations), is.EqualTo(3))
+	_, err = store.Reserve([]string{"41373559241"}, "team-b")
+	then.AssertThat(s.T(), err, is.Nil())
+	_, err = store.Release([]string{reservations[0].Id}, "team-a")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	then.AssertThat(s.T(), len(store.Reservations("team-a")), is.EqualTo(2))
+	then.AssertThat(s.T(), len(store.Reservations("")), is.EqualTo(3))
+	// released IDs are never handed out again
+	isNew, err := store.Issue(idgenerator.NeLoIdType, reservations[0].Id)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), isNew, is.False())
+	_, err = store.Reserve([]string{"41373559241"}, "team-a")
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrIdAlreadyReserved), is.True())
+	_, err = store.Release([]string{reservations[0].Id}, "team-a")
+	then.AssertThat(s.T(), errors.Is(err, idgenerator.ErrIdNotReserved), is.True())
+	// without an owner, nothing is released
+	_, err = store.Release([]string{reservations[1].Id}, "")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+	then.AssertThat(s.T(), len(store.Reservations("team-a")), is.EqualTo(2))
+}
+
+func (s *Suite) Test_Reservation_Store_Ignores_Incomplete_Last_Record() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	content := `{"action":"issue","id":"41373559241","type":"MaLo","time":"2024-01-01T00:00:00Z"}` + "\n" + `{"action":"reser`
+	then.AssertThat(s.T(), os.WriteFile(path, []byte(content), 0o600), is.Nil())
+	store, err := idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), store.IsKnown("41373559241"), is.True())
+	_, err = store.Reserve([]string{"E1137355921"}, "team-a")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), store.Close(), is.Nil())
+
+	store, err = idgenerator.OpenReservationStore(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = store.Close() }()
+	then.AssertThat(s.T(), len(store.Reservations("team-a")), is.EqualTo(1))
+}
+
+func (s *Suite) Test_Generators_Record_Handed_Out_Ids() {
+	path := filepath.Join(s.T().TempDir(), "reservations.jsonl")
+	s.T().Setenv("RESERVATION_STORE_PATH", path)
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "TRID")
+	router := idgenerator.NewRouter()
+	for i := 0; i < 5; i++ {
+		response := performGetRequest(router, "/json")
+		then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	}
+	storeContent, err := os.ReadFile(path)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), strings.Count(string(storeContent), `"action":"issue"`), is.EqualTo(5))
+}
+
+func (s *Suite) Test_Reservation_Endpoints() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/reservations")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotImplemented))
+
+	s.T().Setenv("RESERVATION_STORE_PATH", filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	response = performRequest(router, "POST", "/reservations", strings.NewReader(`{"owner":"team-a","type":"MALO","count":2}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusCreated))
+	var reservations []idgenerator.Reservation
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &reservations), is.Nil())
+	then.AssertThat(s.T(), len(reservations), is.EqualTo(2))
+	then.AssertThat(s.T(), reservations[0].Type, is.EqualTo("MaLo"))
+
+	response = performRequest(router, "POST", "/reservations", strings.NewReader(`{"owner":"team-b","ids":["`+reservations[0].Id+`"]}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusConflict))
+	response = performRequest(router, "POST", "/reservations", strings.NewReader(`{"type":"MALO","count":2}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performRequest(router, "POST", "/reservations/release", strings.NewReader(`{"owner":"team-b","ids":["`+reservations[0].Id+`"]}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusConflict))
+	response = performRequest(router, "POST", "/reservations/release", strings.NewReader(`{"ids":["`+reservations[0].Id+`"]}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performRequest(router, "POST", "/reservations/release", strings.NewReader(`{"owner":"team-a","ids":["`+reservations[0].Id+`"]}`))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+
+	response = performGetRequest(router, "/reservations?owner=team-a")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &reservations), is.Nil())
+	then.AssertThat(s.T(), len(reservations), is.EqualTo(1))
+}
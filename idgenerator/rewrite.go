@@ -0,0 +1,267 @@
+package idgenerator
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// RewriteOptions restrict which IDs in a file are replaced
+type RewriteOptions struct {
+	Type *IdType // Type restricts the replaced IDs to a single type; all types are replaced if nil
+	// Column restricts the replaced IDs to a single column of a CSV file (given by name or 1-based index)
+	Column string
+	// Segment restricts the replaced IDs to EDIFACT segments with this tag (e.g. "LOC") or tag and qualifier (e.g. "LOC+172")
+	Segment string
+}
+
+// csvColumnRanges returns the byte ranges [start, end) of the given column in each record of the CSV data
+func csvColumnRanges(data []byte, column string) ([][2]int, error) {
+	bomLength := 0
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		bomLength = 3
+	}
+	content := data[bomLength:]
+	firstLine, _, _ := strings.Cut(string(content), "\n")
+	csvReader := csv.NewReader(bytes.NewReader(content))
+	if delimiter, ok := detectCsvDelimiter(firstLine); ok {
+		csvReader.Comma = delimiter
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	lineStarts := []int{0}
+	for index, character := range content {
+		if character == '\n' {
+			lineStarts = append(lineStarts, index+1)
+		}
+	}
+	var ranges [][2]int
+	columnIndex := -1
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse CSV: %w", err)
+		}
+		if columnIndex < 0 {
+			if columnIndex, _, err = resolveCsvColumn(record, column); err != nil {
+				return nil, err
+			}
+		}
+		if columnIndex >= len(record) {
+			continue
+		}
+		line, col := csvReader.FieldPos(columnIndex)
+		start := bomLength + lineStarts[line-1] + col - 1
+		end := bomLength + int(csvReader.InputOffset())
+		if columnIndex+1 < len(record) {
+			nextLine, nextCol := csvReader.FieldPos(columnIndex + 1)
+			end = bomLength + lineStarts[nextLine-1] + nextCol - 1
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges, nil
+}
+
+// isInRanges returns true if the byte range [start, end) is completely inside one of the ranges
+func isInRanges(start, end int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if start >= r[0] && end <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// RewriteIds replaces all supported IDs (that match the options) in the given CSV, EDIFACT, JSON or text data with their pseudonyms.
+// As the pseudonyms have the same length as the original IDs, everything but the IDs stays byte-for-byte the same.
+// It returns the rewritten data and the mapping of each replaced ID to its pseudonym (in order of their first occurrence).
+func RewriteIds(data []byte, pseudonymizer *Pseudonymizer, options RewriteOptions) ([]byte, []Pseudonym, error) {
+	var columnRanges [][2]int
+	if options.Column != "" {
+		var err error
+		if columnRanges, err = csvColumnRanges(data, options.Column); err != nil {
+			return nil, nil, err
+		}
+	}
+	if options.Segment != "" {
+		if _, isEdifact := getEdifactSeparators(string(data)); !isEdifact {
+			return nil, nil, fmt.Errorf("the segment option is only supported for EDIFACT interchanges (that start with UNA or UNB)")
+		}
+	}
+	rewritten := make([]byte, len(data))
+	copy(rewritten, data)
+	pseudonyms := map[string]string{}
+	mapping := []Pseudonym{}
+	for _, extractedId := range ExtractIds(string(data), options.Type) {
+		end := extractedId.Offset + len(extractedId.Id)
+		if columnRanges != nil && !isInRanges(extractedId.Offset, end, columnRanges) {
+			continue
+		}
+		if options.Segment != "" && extractedId.Segment != options.Segment && !strings.HasPrefix(extractedId.Segment, options.Segment+"+") {
+			continue
+		}
+		idType, _ := getIdType(extractedId.Type)
+		if _, err := idType.IndexOf(extractedId.Id); err != nil {
+			continue // something that looks similar to an ID but does not match its structure, e.g. "DE" followed by 31 letters
+		}
+		key := idType.Name + ":" + extractedId.Id
+		pseudonym, ok := pseudonyms[key]
+		if !ok {
+			var err error
+			if pseudonym, err = pseudonymizer.Pseudonymize(idType, extractedId.Id); err != nil {
+				return nil, nil, err
+			}
+			pseudonyms[key] = pseudonym
+			mapping = append(mapping, Pseudonym{Id: extractedId.Id, Type: idType.Label, Pseudonym: pseudonym})
+		}
+		copy(rewritten[extractedId.Offset:end], pseudonym)
+	}
+	return rewritten, mapping, nil
+}
+
+// writeMappingCsv writes the mapping table as CSV (with a header row) to w
+func writeMappingCsv(w io.Writer, mapping []Pseudonym) error {
+	csvWriter := csv.NewWriter(w)
+	_ = csvWriter.Write([]string{"type", "id", "pseudonym"})
+	for _, pseudonym := range mapping {
+		_ = csvWriter.Write([]string{pseudonym.Type, pseudonym.Id, pseudonym.Pseudonym})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// newRewritePseudonymizer returns a Pseudonymizer with the given key or, if the key is empty, with a random key.
+// A random key still replaces the same ID consistently within one file, but differently in each run (and it cannot be reversed).
+func newRewritePseudonymizer(key []byte, reversible bool) (*Pseudonymizer, error) {
+	if len(key) == 0 {
+		if reversible {
+			return nil, fmt.Errorf("the reversible mode requires a key")
+		}
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	return NewPseudonymizer(key, reversible)
+}
+
+// rewriteHandler replaces the IDs in the uploaded file with pseudonyms. It returns the rewritten file or, with ?mapping=true, a zip archive that contains both the rewritten file and the mapping table (mapping.csv).
+func rewriteHandler(c *gin.Context) {
+	idType, err := getOptionalIdType(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reversible := c.Query("reversible") == "true"
+	key, _ := getPseudonymizationKey(c, true) // without a key, a random one is used
+	pseudonymizer, err := newRewritePseudonymizer(key, reversible)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, fileName, err := getUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rewritten, mapping, err := RewriteIds(data, pseudonymizer, RewriteOptions{Type: idType, Column: c.Query("column"), Segment: c.Query("segment")})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fileName == "" {
+		fileName = "rewritten"
+	}
+	if c.Query("mapping") != "true" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		c.Data(http.StatusOK, "application/octet-stream", rewritten)
+		return
+	}
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	if fileWriter, zipErr := zipWriter.Create(fileName); zipErr == nil {
+		_, _ = fileWriter.Write(rewritten)
+	}
+	if mappingWriter, zipErr := zipWriter.Create("mapping.csv"); zipErr == nil {
+		_ = writeMappingCsv(mappingWriter, mapping)
+	}
+	if err = zipWriter.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="rewritten.zip"`)
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// rewriteCommand is the CLI equivalent of the rewriteHandler: it replaces the IDs in a file (or stdin) and writes the result to stdout (or -output)
+func rewriteCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("rewrite", stdout)
+	typeName := flagSet.String("type", "", "only replace IDs of this type (MALO, NELO, MELO, TRID or SRID)")
+	column := flagSet.String("column", "", "only replace IDs in this CSV column (name or 1-based index)")
+	segment := flagSet.String("segment", "", "only replace IDs in EDIFACT segments with this tag (e.g. LOC) or tag and qualifier (e.g. LOC+172)")
+	key := flagSet.String("key", os.Getenv("PSEUDONYMIZATION_KEY"), "the pseudonymization key (default: $PSEUDONYMIZATION_KEY); a random key is used if empty")
+	reversible := flagSet.Bool("reversible", false, "create pseudonyms that can be reversed with the key")
+	output := flagSet.String("output", "", "the file to write the rewritten data to (default: stdout)")
+	mappingFile := flagSet.String("mapping", "", "the file to write the mapping table (CSV) to")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 1 {
+		return fmt.Errorf("rewrite expects at most one input file but got %d", flagSet.NArg())
+	}
+	var idType *IdType
+	if *typeName != "" {
+		requestedType, err := getIdType(*typeName)
+		if err != nil {
+			return err
+		}
+		idType = &requestedType
+	}
+	pseudonymizer, err := newRewritePseudonymizer([]byte(*key), *reversible)
+	if err != nil {
+		return err
+	}
+	files, err := openInputFiles(flagSet.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(files[0])
+	_ = files[0].Close()
+	if err != nil {
+		return err
+	}
+	rewritten, mapping, err := RewriteIds(data, pseudonymizer, RewriteOptions{Type: idType, Column: *column, Segment: *segment})
+	if err != nil {
+		return err
+	}
+	if *mappingFile != "" {
+		var mappingCsv bytes.Buffer
+		if err = writeMappingCsv(&mappingCsv, mapping); err != nil {
+			return err
+		}
+		if err = os.WriteFile(*mappingFile, mappingCsv.Bytes(), 0o600); err != nil {
+			return err
+		}
+	}
+	if *output != "" {
+		return os.WriteFile(*output, rewritten, 0o600)
+	}
+	_, err = stdout.Write(rewritten)
+	return err
+}
@@ -0,0 +1,104 @@
+package idgenerator_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (s *Suite) Test_Rewrite_Edifact_Loc_Segments() {
+	pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), false)
+	rewritten, mapping, err := idgenerator.RewriteIds([]byte(utilmdExample), pseudonymizer, idgenerator.RewriteOptions{Segment: "LOC"})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(rewritten), is.EqualTo(len(utilmdExample)))
+	then.AssertThat(s.T(), len(mapping), is.EqualTo(3)) // the NeLo in the FTX segment is not replaced
+	rewrittenText := string(rewritten)
+	then.AssertThat(s.T(), strings.Contains(rewrittenText, "41373559241"), is.False())
+	then.AssertThat(s.T(), strings.Contains(rewrittenText, "E1137355921"), is.True())
+	then.AssertThat(s.T(), strings.Contains(rewrittenText, "LOC+172+"+mapping[0].Pseudonym+"'"), is.True())
+	// everything but the IDs stays the same
+	expected := utilmdExample
+	for _, pseudonym := range mapping {
+		expected = strings.ReplaceAll(expected, "+"+pseudonym.Id+"'", "+"+pseudonym.Pseudonym+"'")
+	}
+	then.AssertThat(s.T(), rewrittenText, is.EqualTo(expected))
+	for _, pseudonym := range mapping {
+		then.AssertThat(s.T(), idgenerator.ValidateValue(nil, pseudonym.Pseudonym).Valid, is.True())
+	}
+}
+
+func (s *Suite) Test_Rewrite_Csv_Column_Consistently() {
+	export := "\xef\xbb\xbfMaLo;Alte MaLo;Bemerkung\r\n" +
+		"41373559241;51238696781;\"Umzug von 51238696781\"\r\n" +
+		"51238696781;;\"41373559241\"\r\n"
+	pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), true)
+	rewritten, mapping, err := idgenerator.RewriteIds([]byte(export), pseudonymizer, idgenerator.RewriteOptions{Column: "MaLo"})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(mapping), is.EqualTo(2))
+	lines := strings.Split(string(rewritten), "\r\n")
+	then.AssertThat(s.T(), lines[0], is.EqualTo("\xef\xbb\xbfMaLo;Alte MaLo;Bemerkung"))
+	then.AssertThat(s.T(), lines[1], is.EqualTo(mapping[0].Pseudonym+";51238696781;\"Umzug von 51238696781\""))
+	then.AssertThat(s.T(), lines[2], is.EqualTo(mapping[1].Pseudonym+";;\"41373559241\""))
+
+	// without a column, all IDs are replaced consistently
+	rewritten, mapping, err = idgenerator.RewriteIds([]byte(export), pseudonymizer, idgenerator.RewriteOptions{})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(mapping), is.EqualTo(2))
+	then.AssertThat(s.T(), strings.Count(string(rewritten), mapping[1].Pseudonym), is.EqualTo(3))
+}
+
+func (s *Suite) Test_Rewrite_Json() {
+	document := `{"marktlokationsId":"41373559241","messlokationen":[{"messlokationsId":"DE0010696664610000000000000012345"}],"zahl":41373559241}`
+	pseudonymizer, _ := idgenerator.NewPseudonymizer([]byte("secret"), false)
+	rewritten, mapping, err := idgenerator.RewriteIds([]byte(document), pseudonymizer, idgenerator.RewriteOptions{})
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(mapping), is.EqualTo(2))
+	then.AssertThat(s.T(), string(rewritten), is.EqualTo(`{"marktlokationsId":"`+mapping[0].Pseudonym+`","messlokationen":[{"messlokationsId":"`+mapping[1].Pseudonym+`"}],"zahl":`+mapping[0].Pseudonym+`}`))
+}
+
+func (s *Suite) Test_Rewrite_Endpoint_With_Mapping() {
+	response := performRequest(idgenerator.NewRouter(), "POST", "/rewrite?mapping=true&type=MALO", strings.NewReader(utilmdExample))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	archive, err := zip.NewReader(bytes.NewReader(response.Body.Bytes()), int64(response.Body.Len()))
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(archive.File), is.EqualTo(2))
+	mappingFile, err := archive.Open("mapping.csv")
+	then.AssertThat(s.T(), err, is.Nil())
+	records, err := csv.NewReader(mappingFile).ReadAll()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(records), is.EqualTo(3)) // header + 2 MaLos
+	rewrittenFile, _ := archive.Open("rewritten")
+	rewritten, _ := io.ReadAll(rewrittenFile)
+	then.AssertThat(s.T(), strings.Contains(string(rewritten), records[1][2]), is.True())
+}
+
+func (s *Suite) Test_Rewrite_Endpoint_Requires_Key_For_Reversible_Mode() {
+	response := performRequest(idgenerator.NewRouter(), "POST", "/rewrite?reversible=true", strings.NewReader(utilmdExample))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
+
+func (s *Suite) Test_Rewrite_Command() {
+	directory := s.T().TempDir()
+	input := filepath.Join(directory, "utilmd.edi")
+	_ = os.WriteFile(input, []byte(utilmdExample), 0o600)
+	output := filepath.Join(directory, "pseudonymized.edi")
+	mappingFile := filepath.Join(directory, "mapping.csv")
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"rewrite", "-key", "secret", "-reversible", "-output", output, "-mapping", mappingFile, input}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	rewritten, err := os.ReadFile(output)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(rewritten), is.EqualTo(len(utilmdExample)))
+	mappingCsv, err := os.ReadFile(mappingFile)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), strings.HasPrefix(string(mappingCsv), "type,id,pseudonym\n"), is.True())
+	then.AssertThat(s.T(), strings.Count(string(mappingCsv), "\n"), is.EqualTo(5)) // header + 2 MaLos, 1 MeLo, 1 NeLo
+}
@@ -0,0 +1,66 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/gin-gonic/gin"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"strings"
+)
+
+func (s *Suite) Test_Routes_Can_Be_Mounted_On_A_Router_Group() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "foobar") // the options take precedence
+	portal := gin.New()
+	tools := portal.Group("/tools")
+	tools.GET("/other", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("malo-id-generator/basePath")) })
+	idgenerator.RegisterRoutes(tools.Group("/ids"), idgenerator.RouteOptions{IdType: "NELO"})
+
+	response := performGetRequest(portal, "/tools/ids/json")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var jsonResponse JsonResponse
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &jsonResponse), is.Nil())
+	then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(jsonResponse.Id), is.Nil())
+
+	response = performGetRequest(portal, "/tools/ids/")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/tools/ids/style"`))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/tools/ids/favicon"`))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/tools/ids/json"`))
+
+	response = performGetRequest(portal, "/tools/ids/style")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining("url(logo)")) // resolved relative to the stylesheet
+
+	response = performGetRequest(portal, "/tools/other")
+	then.AssertThat(s.T(), response.Body.String(), is.EqualTo("")) // the options don't leak into other routes of the group
+}
+
+func (s *Suite) Test_Asset_Links_Of_The_Router_Are_Not_Prefixed() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	response := performGetRequest(idgenerator.NewRouter(), "/")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/style"`))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/favicon"`))
+}
+
+func (s *Suite) Test_Handler_Can_Be_Mounted_On_A_ServeMux() {
+	s.T().Setenv("ID_TYPE_TO_GENERATE", "MALO")
+	mux := http.NewServeMux()
+	mux.Handle("/ids/", idgenerator.NewHandler("/ids", idgenerator.RouteOptions{WithoutFunctionTriggers: true}))
+
+	response := performGetRequest(mux, "/ids/explain?id=41373559241")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Body.String(), is.StringContaining(`href="/ids/style"`))
+
+	response = performGetRequest(mux, "/ids/favicon")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Header().Get("Content-Type"), is.EqualTo("image/png"))
+
+	response = performRequest(mux, "POST", "/ids/validate", strings.NewReader("41373559241\n"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+
+	response = performRequest(mux, "POST", "/ids/generate-queue", strings.NewReader("{}"))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotFound))
+}
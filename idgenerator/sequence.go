@@ -0,0 +1,224 @@
+package idgenerator
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sequenceKeyHeader is the HTTP header in which callers may provide their own sequence key (instead of the server's SEQUENCE_KEY)
+const sequenceKeyHeader = "X-Sequence-Key"
+
+// maxSequenceCount is the maximum number of IDs that are returned for a single request
+const maxSequenceCount = 1000
+
+// A SequenceGenerator enumerates the ID space of a type in an order that is determined by a secret key: the n-th ID is the ID at the position of n in a keyed permutation of the ID space.
+// Distinct counters never result in the same ID, so that instances that share the key but use distinct counters (e.g. disjoint ranges) never collide, without any shared state.
+type SequenceGenerator struct {
+	idType      IdType
+	permutation keyedPermutation
+}
+
+// NewSequenceGenerator returns the SequenceGenerator for the given key and type
+func NewSequenceGenerator(key []byte, idType IdType) (*SequenceGenerator, error) {
+	permutation, err := newKeyedPermutation(key, "sequence:"+idType.Name, idType.SpaceSize())
+	if err != nil {
+		return nil, err
+	}
+	return &SequenceGenerator{idType: idType, permutation: permutation}, nil
+}
+
+// Size returns the number of distinct counters (which is the number of distinct IDs of the type)
+func (g *SequenceGenerator) Size() *big.Int {
+	return g.idType.SpaceSize()
+}
+
+// IdAt returns the counter-th ID of the sequence. The counter has to be in [0, Size()).
+func (g *SequenceGenerator) IdAt(counter *big.Int) (string, error) {
+	index, err := g.permutation.Permute(counter)
+	if err != nil {
+		return "", err
+	}
+	return g.idType.IdAt(index)
+}
+
+// CounterOf returns the counter at which the id appears in the sequence (the inverse of IdAt)
+func (g *SequenceGenerator) CounterOf(id string) (*big.Int, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if err := g.idType.Validate(id); err != nil {
+		return nil, err
+	}
+	index, err := g.idType.IndexOf(id)
+	if err != nil {
+		return nil, err
+	}
+	return g.permutation.Invert(index)
+}
+
+// A SequenceId is an ID together with its counter in the sequence
+type SequenceId struct {
+	Counter *big.Int `json:"counter"`
+	Id      string   `json:"id"`
+	Type    string   `json:"type"`
+}
+
+// Range returns the IDs for the count counters starting at start. IDs on the exclusion list (if configured) are skipped, so that fewer than count IDs may be returned.
+func (g *SequenceGenerator) Range(start *big.Int, count int) ([]SequenceId, error) {
+	exclusions, err := getExclusionList()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SequenceId, 0, count)
+	counter := new(big.Int).Set(start)
+	for i := 0; i < count; i++ {
+		id, idErr := g.IdAt(counter)
+		if idErr != nil {
+			return nil, idErr
+		}
+		if exclusions.rejects(g.idType.Label, id) {
+			log.Printf("Skipped the %s '%s' (counter %s) because it's on the exclusion list", g.idType.Label, id, counter.String())
+		} else {
+			result = append(result, SequenceId{Counter: new(big.Int).Set(counter), Id: id, Type: g.idType.Label})
+		}
+		counter.Add(counter, big.NewInt(1))
+	}
+	return result, nil
+}
+
+// getSequenceKey returns the key from the request header or, if the header is not set, from the environment variable SEQUENCE_KEY
+func getSequenceKey(c *gin.Context) ([]byte, error) {
+	if key := c.GetHeader(sequenceKeyHeader); key != "" {
+		return []byte(key), nil
+	}
+	if key, err := getServerSequenceKey(); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("neither the '%s' header nor the environment variable 'SEQUENCE_KEY' is set", sequenceKeyHeader)
+}
+
+// getServerSequenceKey returns the key from the environment variable SEQUENCE_KEY
+func getServerSequenceKey() ([]byte, error) {
+	if key, ok := os.LookupEnv("SEQUENCE_KEY"); ok && key != "" {
+		return []byte(key), nil
+	}
+	return nil, fmt.Errorf("the environment variable 'SEQUENCE_KEY' is not set")
+}
+
+// parseCounter parses a non-negative decimal counter
+func parseCounter(value string) (*big.Int, error) {
+	counter, ok := new(big.Int).SetString(value, 10)
+	if !ok || counter.Sign() < 0 {
+		return nil, fmt.Errorf("the counter has to be a non-negative integer but was '%s'", value)
+	}
+	return counter, nil
+}
+
+// parseSequenceCount parses the number of requested IDs (1 if empty)
+func parseSequenceCount(value string) (int, error) {
+	if value == "" {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 || count > maxSequenceCount {
+		return 0, fmt.Errorf("the count has to be between 1 and %d but was '%s'", maxSequenceCount, value)
+	}
+	return count, nil
+}
+
+// sequenceHandler returns the IDs of the sequence for ?n=<counter> (and the following counters with &count=). With ?id=<ID> it returns the counter of the ID instead.
+// The type is taken from ?type= or the configured ID_TYPE_TO_GENERATE.
+func sequenceHandler(c *gin.Context) {
+	key, err := getSequenceKey(c)
+	if err != nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	idType, err := getIdTypeOrConfiguredFor(c, c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	generator, err := NewSequenceGenerator(key, idType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if id := c.Query("id"); id != "" {
+		counter, counterErr := generator.CounterOf(id)
+		if counterErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": counterErr.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, SequenceId{Counter: counter, Id: strings.ToUpper(strings.TrimSpace(id)), Type: idType.Label})
+		return
+	}
+	start, err := parseCounter(c.Query("n"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	count, err := parseSequenceCount(c.Query("count"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids, err := generator.Range(start, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ids)
+}
+
+// sequenceCommand is the CLI equivalent of the sequenceHandler: it prints the IDs for a range of counters (or the counter of an ID with -id)
+func sequenceCommand(args []string, _ io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("sequence", stdout)
+	typeName := flagSet.String("type", "MALO", "the ID type (MALO, NELO, MELO, TRID or SRID)")
+	key := flagSet.String("key", os.Getenv("SEQUENCE_KEY"), "the sequence key (default: $SEQUENCE_KEY)")
+	start := flagSet.String("n", "0", "the first counter")
+	count := flagSet.String("count", "1", fmt.Sprintf("the number of IDs (at most %d)", maxSequenceCount))
+	id := flagSet.String("id", "", "print the counter of this ID instead")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	idType, err := getIdType(*typeName)
+	if err != nil {
+		return err
+	}
+	generator, err := NewSequenceGenerator([]byte(*key), idType)
+	if err != nil {
+		return err
+	}
+	if *id != "" {
+		counter, counterErr := generator.CounterOf(*id)
+		if counterErr != nil {
+			return counterErr
+		}
+		_, err = fmt.Fprintln(stdout, counter.String())
+		return err
+	}
+	startCounter, err := parseCounter(*start)
+	if err != nil {
+		return err
+	}
+	idCount, err := parseSequenceCount(*count)
+	if err != nil {
+		return err
+	}
+	ids, err := generator.Range(startCounter, idCount)
+	if err != nil {
+		return err
+	}
+	for _, sequenceId := range ids {
+		if _, err = fmt.Fprintln(stdout, sequenceId.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
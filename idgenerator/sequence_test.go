@@ -0,0 +1,81 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+func (s *Suite) Test_Sequence_Is_Collision_Free_And_Reproducible() {
+	for _, idType := range allIdTypes {
+		generator, err := idgenerator.NewSequenceGenerator([]byte("shared key"), idType)
+		then.AssertThat(s.T(), err, is.Nil())
+		// two "instances" with disjoint counter ranges
+		first, err := generator.Range(big.NewInt(0), 500)
+		then.AssertThat(s.T(), err, is.Nil())
+		second, err := generator.Range(big.NewInt(500), 500)
+		then.AssertThat(s.T(), err, is.Nil())
+		seen := map[string]bool{}
+		for _, sequenceId := range append(first, second...) {
+			then.AssertThat(s.T(), seen[sequenceId.Id], is.False())
+			seen[sequenceId.Id] = true
+			then.AssertThat(s.T(), idType.Validate(sequenceId.Id), is.Nil())
+			counter, counterErr := generator.CounterOf(sequenceId.Id)
+			then.AssertThat(s.T(), counterErr, is.Nil())
+			then.AssertThat(s.T(), counter.Cmp(sequenceId.Counter), is.EqualTo(0))
+		}
+		sameId, _ := generator.IdAt(big.NewInt(42))
+		then.AssertThat(s.T(), sameId, is.EqualTo(first[42].Id))
+		otherGenerator, _ := idgenerator.NewSequenceGenerator([]byte("other key"), idType)
+		otherId, _ := otherGenerator.IdAt(big.NewInt(42))
+		then.AssertThat(s.T(), otherId == sameId, is.False())
+	}
+	// the IDs look random rather than sequential
+	generator, _ := idgenerator.NewSequenceGenerator([]byte("shared key"), idgenerator.MaLoIdType)
+	ids, _ := generator.Range(big.NewInt(0), 2)
+	then.AssertThat(s.T(), ids[0].Id[:6] == ids[1].Id[:6], is.False())
+	_, err := generator.IdAt(idgenerator.MaLoIdType.SpaceSize())
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Sequence_Endpoint() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/sequence?n=0&type=NELO")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusNotImplemented))
+
+	s.T().Setenv("SEQUENCE_KEY", "shared key")
+	response = performGetRequest(router, "/sequence?n=1000&count=3&type=NELO")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var ids []idgenerator.SequenceId
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &ids), is.Nil())
+	then.AssertThat(s.T(), len(ids), is.EqualTo(3))
+	then.AssertThat(s.T(), ids[2].Counter.Int64(), is.EqualTo(int64(1002)))
+	then.AssertThat(s.T(), ids[2].Type, is.EqualTo("NeLo"))
+
+	response = performGetRequest(router, "/sequence?type=NELO&id="+ids[1].Id)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var sequenceId idgenerator.SequenceId
+	then.AssertThat(s.T(), json.Unmarshal(response.Body.Bytes(), &sequenceId), is.Nil())
+	then.AssertThat(s.T(), sequenceId.Counter.Int64(), is.EqualTo(int64(1001)))
+
+	response = performGetRequest(router, "/sequence?n=-1&type=NELO")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performGetRequest(router, "/sequence?n=0&count=1001&type=NELO")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
+
+func (s *Suite) Test_Sequence_Command() {
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"sequence", "-key", "shared key", "-type", "TRID", "-n", "7", "-count", "2"}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	lines := strings.Fields(stdout.String())
+	then.AssertThat(s.T(), len(lines), is.EqualTo(2))
+	generator, _ := idgenerator.NewSequenceGenerator([]byte("shared key"), idgenerator.TRIdType)
+	expected, _ := generator.IdAt(big.NewInt(8))
+	then.AssertThat(s.T(), lines[1], is.EqualTo(expected))
+}
@@ -0,0 +1,83 @@
+package idgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// streamFlushInterval is the number of IDs after which the streamed response is flushed to the client
+const streamFlushInterval = 100
+
+// maxRecordedStreamCount is the maximum count of a stream if a reservation store is configured, which records (and keeps in memory) every handed out ID
+const maxRecordedStreamCount = 1_000_000
+
+// streamHandler writes freshly generated IDs to the client as they are generated (with chunked transfer encoding), either as newline-delimited JSON (the same objects as /json) or, with ?format=lines, as plain lines.
+// Use ?type= to choose the type (default: the configured ID_TYPE_TO_GENERATE) and ?count= to limit the number of IDs; without a count, IDs are streamed until the client disconnects.
+// Without a reservation store, nothing but the current ID is kept in memory. With a reservation store, every ID is recorded, so a count (of at most maxRecordedStreamCount) is required.
+func streamHandler(c *gin.Context) {
+	idType, err := getIdTypeOrConfiguredFor(c, c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	count := int64(0)
+	if countParameter := c.Query("count"); countParameter != "" {
+		if count, err = strconv.ParseInt(countParameter, 10, 64); err != nil || count < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the count has to be a non-negative integer but was '%s'", countParameter)})
+			return
+		}
+	}
+	store, err := getReservationStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if store != nil && (count == 0 || count > maxRecordedStreamCount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a count between 1 and %d is required because every streamed ID is recorded in the reservation store", maxRecordedStreamCount)})
+		return
+	}
+	format := c.DefaultQuery("format", "ndjson")
+	switch format {
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+	case "lines":
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format '%s'. Supported formats are 'ndjson' and 'lines'", format)})
+		return
+	}
+	c.Header("X-Content-Type-Options", "nosniff") // otherwise browsers buffer the response to sniff its type
+	c.Status(http.StatusOK)
+	generator := idType.Generator()
+	done := c.Request.Context().Done()
+	for generated := int64(0); count == 0 || generated < count; generated++ {
+		select {
+		case <-done:
+			log.Printf("Stopped streaming after %d IDs because the client disconnected", generated)
+			return
+		default:
+		}
+		rawId, generationErr := generateUnusedIdDictionary(generator)
+		var line []byte
+		switch {
+		case generationErr != nil:
+			// the status code has already been sent, so the error can only be reported in the body
+			line, _ = json.Marshal(gin.H{"error": generationErr.Error()})
+		case format == "lines":
+			line = []byte(rawId["id"])
+		default:
+			line, _ = json.Marshal(rawId)
+		}
+		if _, writeErr := c.Writer.Write(append(line, '\n')); writeErr != nil || generationErr != nil {
+			return
+		}
+		if (generated+1)%streamFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+	}
+	c.Writer.Flush()
+}
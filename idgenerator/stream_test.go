@@ -0,0 +1,67 @@
+package idgenerator_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (s *Suite) Test_Stream_Ndjson_With_Count() {
+	response := performGetRequest(idgenerator.NewRouter(), "/stream?type=NELO&count=250")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), response.Header().Get("Content-Type"), is.EqualTo("application/x-ndjson"))
+	lines := strings.Split(strings.TrimSuffix(response.Body.String(), "\n"), "\n")
+	then.AssertThat(s.T(), len(lines), is.EqualTo(250))
+	for _, line := range lines {
+		var jsonResponse JsonResponse
+		then.AssertThat(s.T(), json.Unmarshal([]byte(line), &jsonResponse), is.Nil())
+		then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(jsonResponse.Id), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Stream_Stops_When_Client_Disconnects() {
+	server := httptest.NewServer(idgenerator.NewRouter())
+	response, err := http.Get(server.URL + "/stream?type=MALO&format=lines") // endless
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), response.TransferEncoding, is.EqualTo([]string{"chunked"}))
+	scanner := bufio.NewScanner(response.Body)
+	for i := 0; i < 1000 && scanner.Scan(); i++ {
+		then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(scanner.Text()), is.Nil())
+	}
+	_ = response.Body.Close()
+	// Close blocks until all handlers have returned, so this only finishes if the handler stopped
+	closed := make(chan bool)
+	go func() {
+		server.Close()
+		closed <- true
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		s.T().Fatal("the stream did not stop after the client disconnected")
+	}
+}
+
+func (s *Suite) Test_Stream_Rejects_Unknown_Format() {
+	response := performGetRequest(idgenerator.NewRouter(), "/stream?type=MALO&format=xml")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
+
+func (s *Suite) Test_Stream_Requires_Count_With_Reservation_Store() {
+	s.T().Setenv("RESERVATION_STORE_PATH", filepath.Join(s.T().TempDir(), "reservations.jsonl"))
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/stream?type=MALO")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performGetRequest(router, "/stream?type=MALO&count=1000001")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+	response = performGetRequest(router, "/stream?type=MALO&count=10&format=lines")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Count(response.Body.String(), "\n"), is.EqualTo(10))
+}
@@ -0,0 +1,132 @@
+package idgenerator
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// confusableCharacters are pairs of characters that look alike and are hence often mixed up when IDs are read from paper, screenshots or bad fonts
+var confusableCharacters = map[rune]string{
+	'0': "OD", 'O': "0D", 'D': "0O",
+	'1': "IL7", 'I': "1L", 'L': "1I", '7': "1",
+	'2': "Z", 'Z': "2",
+	'5': "S", 'S': "5",
+	'6': "G", 'G': "6",
+	'8': "B", 'B': "8",
+}
+
+// relative weights of the different kinds of typos; they are normalized, so only their ratio matters.
+// Adjacent transpositions and mixing up similar looking characters are much more common than arbitrary substitutions.
+const (
+	transpositionWeight          = 1.0
+	confusableSubstitutionWeight = 1.0
+	neighbourSubstitutionWeight  = 0.5
+	otherSubstitutionWeight      = 0.2
+)
+
+// An IdSuggestion is a valid ID that differs from an invalid ID by a single typo
+type IdSuggestion struct {
+	Id          string  `json:"id"`
+	Kind        string  `json:"kind"`        // Kind is either "substitution" or "transposition"
+	Position    int     `json:"position"`    // Position is the (1-based) position of the (first) character that differs from the invalid ID
+	Original    string  `json:"original"`    // Original are the character(s) of the invalid ID that were replaced
+	Replacement string  `json:"replacement"` // Replacement are the character(s) of the suggested ID
+	Likelihood  float64 `json:"likelihood"`  // Likelihood is the estimated probability that this suggestion is the ID that was meant (all likelihoods add up to 1)
+}
+
+// substitutionWeight estimates how likely it is that someone typed the character typed although they meant intended
+func substitutionWeight(intended, typed rune) float64 {
+	if strings.ContainsRune(confusableCharacters[intended], typed) {
+		return confusableSubstitutionWeight
+	}
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	if isDigit(intended) && isDigit(typed) && (intended-typed == 1 || typed-intended == 1) {
+		// neighbouring keys on the number row or the numpad
+		return neighbourSubstitutionWeight
+	}
+	return otherSubstitutionWeight
+}
+
+// SuggestCorrections returns all valid IDs of the given type that can be reached from id by a single substitution or an adjacent transposition.
+// The suggestions are sorted by descending likelihood. If id is already valid, there are no suggestions.
+func SuggestCorrections(idType IdType, id string) ([]IdSuggestion, error) {
+	if !idType.HasChecksum() {
+		return nil, fmt.Errorf("%s-IDs have no checksum, so typos cannot be detected", idType.Label)
+	}
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if len(id) != idType.Length {
+		return nil, fmt.Errorf("a %s-ID must be %d characters long but '%s' is %d characters long", idType.Label, idType.Length, id, len(id))
+	}
+	suggestions := []IdSuggestion{}
+	if idType.Validate(id) == nil {
+		return suggestions, nil
+	}
+	for _, variant := range singleTypoVariants(id) {
+		if idType.Validate(variant.id) != nil {
+			continue
+		}
+		// the variant of the invalid ID is the valid ID that was probably meant, so the replacement is what was intended
+		likelihood := transpositionWeight
+		if variant.kind == substitutionTypo {
+			likelihood = substitutionWeight([]rune(variant.replacement)[0], []rune(variant.original)[0])
+		}
+		suggestions = append(suggestions, IdSuggestion{
+			Id:          variant.id,
+			Kind:        variant.kind,
+			Position:    variant.position,
+			Original:    variant.original,
+			Replacement: variant.replacement,
+			Likelihood:  likelihood,
+		})
+	}
+	totalWeight := 0.0
+	for _, suggestion := range suggestions {
+		totalWeight += suggestion.Likelihood
+	}
+	for i := range suggestions {
+		suggestions[i].Likelihood /= totalWeight
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Likelihood > suggestions[j].Likelihood
+	})
+	return suggestions, nil
+}
+
+// getRequestedIdType returns the IdType from the "type" query parameter or, if it's not set, guesses the type from the given id
+func getRequestedIdType(c *gin.Context, id string) (IdType, error) {
+	if typeName := c.Query("type"); typeName != "" {
+		return getIdType(typeName)
+	}
+	if idType, ok := detectIdType(id); ok {
+		return idType, nil
+	}
+	return IdType{}, fmt.Errorf("could not detect the type of '%s'; please provide the 'type' query parameter", id)
+}
+
+// suggestionsHandler returns the possible corrections for the ID in the "id" query parameter as JSON
+func suggestionsHandler(c *gin.Context) {
+	id := strings.ToUpper(strings.TrimSpace(c.Query("id")))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the query parameter 'id' is required"})
+		return
+	}
+	idType, err := getRequestedIdType(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	suggestions, err := SuggestCorrections(idType, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":          id,
+		"type":        idType.Label,
+		"valid":       idType.Validate(id) == nil,
+		"suggestions": suggestions,
+	})
+}
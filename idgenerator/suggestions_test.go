@@ -0,0 +1,80 @@
+package idgenerator_test
+
+import (
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http"
+)
+
+type SuggestionsResponse struct {
+	Id          string                     `json:"id"`
+	Type        string                     `json:"type"`
+	Valid       bool                       `json:"valid"`
+	Suggestions []idgenerator.IdSuggestion `json:"suggestions"`
+}
+
+func suggestedIds(suggestions []idgenerator.IdSuggestion) []string {
+	result := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		result[i] = suggestion.Id
+	}
+	return result
+}
+
+func (s *Suite) Test_Suggestions_Contain_The_Id_With_The_Correct_Checksum() {
+	// 41373559241 is the example MaLo from the BDEW documentation
+	suggestions, err := idgenerator.SuggestCorrections(idgenerator.MaLoIdType, "41373559240")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), suggestedIds(suggestions), is.ArrayContaining("41373559241"))
+	for _, suggestion := range suggestions {
+		then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(suggestion.Id), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Transposition_Is_Ranked_Higher_Than_Arbitrary_Substitutions() {
+	suggestions, err := idgenerator.SuggestCorrections(idgenerator.MaLoIdType, "41375359241") // "35" transposed
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(suggestions), is.GreaterThan(1))
+	for _, suggestion := range suggestions {
+		if suggestion.Id == "41373559241" {
+			then.AssertThat(s.T(), suggestion.Kind, is.EqualTo("transposition"))
+			then.AssertThat(s.T(), suggestion.Position, is.EqualTo(5))
+			then.AssertThat(s.T(), suggestion.Likelihood, is.EqualTo(suggestions[0].Likelihood)) // there may be equally likely suggestions, e.g. 1 instead of 7
+		} else if suggestion.Kind == "substitution" && suggestion.Replacement == "8" {
+			then.AssertThat(s.T(), suggestion.Likelihood, is.LessThan(suggestions[0].Likelihood))
+		}
+	}
+}
+
+func (s *Suite) Test_Valid_Ids_Have_No_Suggestions() {
+	suggestions, err := idgenerator.SuggestCorrections(idgenerator.MaLoIdType, "41373559241")
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(suggestions), is.EqualTo(0))
+}
+
+func (s *Suite) Test_MeLos_Cannot_Be_Corrected() {
+	_, err := idgenerator.SuggestCorrections(idgenerator.MeLoIdType, "DE0010696664610000000000000012345")
+	then.AssertThat(s.T(), err, is.Not(is.Nil()))
+}
+
+func (s *Suite) Test_Suggestions_Endpoint() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/suggest?id=E1234567890")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var suggestionsResponse SuggestionsResponse
+	err := json.NewDecoder(response.Body).Decode(&suggestionsResponse)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), suggestionsResponse.Type, is.EqualTo("NeLo"))
+	then.AssertThat(s.T(), len(suggestionsResponse.Suggestions), is.GreaterThan(0))
+	for _, suggestion := range suggestionsResponse.Suggestions {
+		then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(suggestion.Id), is.Nil())
+	}
+}
+
+func (s *Suite) Test_Suggestions_Endpoint_Requires_An_Id() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/suggest")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
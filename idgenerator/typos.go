@@ -0,0 +1,54 @@
+package idgenerator
+
+// typoCandidateCharacters are all characters that may replace a character of an ID when looking for typos
+var typoCandidateCharacters = []rune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+const (
+	substitutionTypo  = "substitution"
+	transpositionTypo = "transposition"
+)
+
+// A typoVariant is what an ID looks like after a single typo
+type typoVariant struct {
+	id          string
+	kind        string // kind is either substitutionTypo or transpositionTypo
+	position    int    // position is the (1-based) position of the (first) character that differs from the original ID
+	original    string // original are the character(s) of the original ID that were replaced
+	replacement string // replacement are the character(s) that the typo introduced
+}
+
+// singleTypoVariants returns all variants of id that differ from it by either a single substitution (with any of the typoCandidateCharacters) or by swapping two adjacent (different) characters
+func singleTypoVariants(id string) []typoVariant {
+	characters := []rune(id)
+	var variants []typoVariant
+	for index, original := range characters {
+		for _, replacement := range typoCandidateCharacters {
+			if replacement == original {
+				continue
+			}
+			variant := make([]rune, len(characters))
+			copy(variant, characters)
+			variant[index] = replacement
+			variants = append(variants, typoVariant{
+				id:          string(variant),
+				kind:        substitutionTypo,
+				position:    index + 1,
+				original:    string(original),
+				replacement: string(replacement),
+			})
+		}
+		if index+1 < len(characters) && characters[index] != characters[index+1] {
+			variant := make([]rune, len(characters))
+			copy(variant, characters)
+			variant[index], variant[index+1] = variant[index+1], variant[index]
+			variants = append(variants, typoVariant{
+				id:          string(variant),
+				kind:        transpositionTypo,
+				position:    index + 1,
+				original:    string(characters[index : index+2]),
+				replacement: string(variant[index : index+2]),
+			})
+		}
+	}
+	return variants
+}
@@ -0,0 +1,349 @@
+package idgenerator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxUploadSize is the maximum size of files that are uploaded to the HTTP endpoints (10MB)
+const maxUploadSize = 10 << 20
+
+// A ValidationResult describes whether a single value from an uploaded file is a valid ID
+type ValidationResult struct {
+	Row               int    `json:"row"` // Row is the 1-based line/record number in the input (the header counts, too)
+	Value             string `json:"value"`
+	Type              string `json:"type,omitempty"` // Type is the requested or detected ID type; empty if it could not be detected
+	Valid             bool   `json:"valid"`
+	Reason            string `json:"reason,omitempty"`            // Reason describes why the value is invalid
+	CorrectedChecksum string `json:"correctedChecksum,omitempty"` // CorrectedChecksum is set if the value has the correct structure but the wrong checksum
+	CorrectedId       string `json:"correctedId,omitempty"`       // CorrectedId is the value with the CorrectedChecksum
+}
+
+// ValidateValue validates a single value. If idType is nil, the type is detected from the value.
+func ValidateValue(idType *IdType, value string) ValidationResult {
+	result := ValidationResult{Value: value}
+	id := strings.ToUpper(strings.TrimSpace(value))
+	if idType == nil {
+		detectedType, ok := detectIdType(id)
+		if !ok {
+			result.Reason = "the value does not look like any of the supported ID types"
+			return result
+		}
+		idType = &detectedType
+	}
+	result.Type = idType.Label
+	err := idType.Validate(id)
+	if err == nil {
+		result.Valid = true
+		return result
+	}
+	result.Reason = err.Error()
+	if idType.HasChecksum() && len(id) == idType.Length && idType.pattern.MatchString(id[:idType.Length-1]+"0") {
+		// the structure is ok, only the checksum is wrong
+		if checksum, checksumErr := idType.CalculateChecksum(id); checksumErr == nil {
+			result.CorrectedChecksum = checksum
+			result.CorrectedId = id[:idType.Length-1] + checksum
+		}
+	}
+	return result
+}
+
+// bulkInputOptions describe where to find the IDs in a CSV or plain text input
+type bulkInputOptions struct {
+	// column is either the name of the CSV column (from the header row) or its 1-based index. If empty, the first column is used.
+	column string
+	// header is "true", "false" or "auto" (the default). In auto mode, the first row is treated as header if the column is given by name or if its value does not look like an ID.
+	header string
+}
+
+// A bulkValue is a single value from a CSV or plain text input
+type bulkValue struct {
+	row   int
+	value string
+}
+
+// detectCsvDelimiter returns the delimiter that is used in the first line of a CSV file. Excel uses ";" in German locales.
+func detectCsvDelimiter(firstLine string) (rune, bool) {
+	for _, delimiter := range []rune{';', '\t', ','} {
+		if strings.ContainsRune(firstLine, delimiter) {
+			return delimiter, true
+		}
+	}
+	return 0, false
+}
+
+// resolveCsvColumn returns the 0-based index of the column that is given either by its name (from the header row) or its 1-based index.
+// If column is empty, the first column is used. The second return value is true if the column was given by name.
+func resolveCsvColumn(header []string, column string) (int, bool, error) {
+	if column == "" {
+		return 0, false, nil
+	}
+	if index, err := strconv.Atoi(column); err == nil {
+		if index < 1 {
+			return 0, false, fmt.Errorf("the column index must be >= 1 but is %d", index)
+		}
+		return index - 1, false, nil
+	}
+	for index, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return index, true, nil
+		}
+	}
+	return 0, true, fmt.Errorf("there is no column '%s' in the header row %v", column, header)
+}
+
+// readBulkValues reads the values of a single column from CSV data or all lines from newline separated text.
+func readBulkValues(r io.Reader, options bulkInputOptions) ([]bulkValue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel likes to write byte order marks
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	var records [][]string
+	if delimiter, isCsv := detectCsvDelimiter(firstLine); isCsv {
+		csvReader := csv.NewReader(bytes.NewReader(data))
+		csvReader.Comma = delimiter
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		if records, err = csvReader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("could not parse CSV: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			records = append(records, []string{strings.TrimSpace(scanner.Text())})
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(records) == 0 {
+		return []bulkValue{}, nil
+	}
+	columnIndex, columnIsName, err := resolveCsvColumn(records[0], options.column)
+	if err != nil {
+		return nil, err
+	}
+	skipFirstRow := false
+	switch options.header {
+	case "true":
+		skipFirstRow = true
+	case "", "auto":
+		if columnIsName {
+			skipFirstRow = true
+		} else if columnIndex < len(records[0]) {
+			_, looksLikeAnId := detectIdType(records[0][columnIndex])
+			skipFirstRow = !looksLikeAnId
+		}
+	case "false":
+	default:
+		return nil, fmt.Errorf("header must be 'true', 'false' or 'auto' but is '%s'", options.header)
+	}
+	values := make([]bulkValue, 0, len(records))
+	for index, record := range records {
+		if index == 0 && skipFirstRow {
+			continue
+		}
+		if columnIndex >= len(record) || strings.TrimSpace(record[columnIndex]) == "" {
+			continue
+		}
+		values = append(values, bulkValue{row: index + 1, value: strings.TrimSpace(record[columnIndex])})
+	}
+	return values, nil
+}
+
+// ValidateBulk validates all values from the given CSV or plain text input. If idType is nil, the type of each value is detected separately.
+func ValidateBulk(r io.Reader, idType *IdType, options bulkInputOptions) ([]ValidationResult, error) {
+	values, err := readBulkValues(r, options)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ValidationResult, 0, len(values))
+	for _, value := range values {
+		result := ValidateValue(idType, value.value)
+		result.Row = value.row
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// writeValidationResultsCsv writes the results as CSV (with a header row) to w
+func writeValidationResultsCsv(w io.Writer, results []ValidationResult) error {
+	csvWriter := csv.NewWriter(w)
+	_ = csvWriter.Write([]string{"row", "value", "type", "valid", "reason", "correctedChecksum", "correctedId"})
+	for _, result := range results {
+		_ = csvWriter.Write([]string{strconv.Itoa(result.Row), result.Value, result.Type, strconv.FormatBool(result.Valid), result.Reason, result.CorrectedChecksum, result.CorrectedId})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// validationTemplateData returns the data that the validation.tmpl.html template needs
+func validationTemplateData(results []ValidationResult, fileName string) gin.H {
+	numberOfValidIds := 0
+	for _, result := range results {
+		if result.Valid {
+			numberOfValidIds++
+		}
+	}
+	return gin.H{
+		"results":           results,
+		"fileName":          fileName,
+		"numberOfValidIds":  numberOfValidIds,
+		"numberOfInvalid":   len(results) - numberOfValidIds,
+		"recruitingMessage": template.HTML(recruitingMessage),
+	}
+}
+
+// getUploadedFile returns the file from the multipart form field "file" or, if the request is not a multipart form, the request body
+func getUploadedFile(c *gin.Context) (io.ReadCloser, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("the multipart form must contain a field 'file': %w", err)
+		}
+		file, err := fileHeader.Open()
+		return file, fileHeader.Filename, err
+	}
+	return c.Request.Body, "", nil
+}
+
+// getBulkInputOptions reads the bulkInputOptions and the optional ID type from the query (or multipart form) parameters
+func getBulkInputOptions(c *gin.Context) (*IdType, bulkInputOptions, error) {
+	options := bulkInputOptions{column: c.Query("column"), header: c.DefaultQuery("header", "auto")}
+	if options.column == "" {
+		options.column = c.PostForm("column")
+	}
+	typeName := c.Query("type")
+	if typeName == "" {
+		typeName = c.PostForm("type")
+	}
+	if typeName == "" {
+		return nil, options, nil
+	}
+	idType, err := getIdType(typeName)
+	if err != nil {
+		return nil, options, err
+	}
+	return &idType, options, nil
+}
+
+// validationFormHandler renders an HTML form to upload files for validation
+func validationFormHandler(c *gin.Context) {
+	renderHtml(c, "static/templates/validation.tmpl.html", validationTemplateData(nil, ""))
+}
+
+// bulkValidationHandler validates all IDs of an uploaded CSV or text file and returns the report as JSON (default), CSV or HTML (?format=csv or ?format=html)
+func bulkValidationHandler(c *gin.Context) {
+	file, fileName, err := getUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer func() { _ = file.Close() }()
+	idType, options, err := getBulkInputOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := ValidateBulk(file, idType, options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch format := c.DefaultQuery("format", c.DefaultPostForm("format", "json")); format {
+	case "json":
+		c.JSON(http.StatusOK, results)
+	case "csv":
+		var buffer bytes.Buffer
+		if err = writeValidationResultsCsv(&buffer, results); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+	case "html":
+		renderHtml(c, "static/templates/validation.tmpl.html", validationTemplateData(results, fileName))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format '%s'. Supported formats are 'json', 'csv' and 'html'", format)})
+	}
+}
+
+// openInputFiles returns a reader for each of the given file names or stdin if there are no file names ("-" means stdin, too)
+func openInputFiles(fileNames []string, stdin io.Reader) ([]io.ReadCloser, error) {
+	if len(fileNames) == 0 {
+		return []io.ReadCloser{io.NopCloser(stdin)}, nil
+	}
+	var files []io.ReadCloser
+	for _, fileName := range fileNames {
+		if fileName == "-" {
+			files = append(files, io.NopCloser(stdin))
+			continue
+		}
+		file, err := os.Open(fileName)
+		if err != nil {
+			for _, openedFile := range files {
+				_ = openedFile.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// validateCommand is the CLI equivalent of the bulkValidationHandler: it validates the IDs from the given files (or stdin)
+func validateCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := newFlagSet("validate", stdout)
+	typeName := flagSet.String("type", "", "the ID type (MALO, NELO, MELO, TRID or SRID); detected for each value if not set")
+	column := flagSet.String("column", "", "the name or 1-based index of the CSV column that contains the IDs; the first column if not set")
+	header := flagSet.String("header", "auto", "whether the first row is a header row: true, false or auto")
+	format := flagSet.String("format", "json", "the output format: json, csv or html")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	var idType *IdType
+	if *typeName != "" {
+		requestedType, err := getIdType(*typeName)
+		if err != nil {
+			return err
+		}
+		idType = &requestedType
+	}
+	files, err := openInputFiles(flagSet.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	results := []ValidationResult{}
+	for _, file := range files {
+		fileResults, validationErr := ValidateBulk(file, idType, bulkInputOptions{column: *column, header: *header})
+		_ = file.Close()
+		if validationErr != nil {
+			return validationErr
+		}
+		results = append(results, fileResults...)
+	}
+	switch *format {
+	case "json":
+		return writeJson(stdout, results)
+	case "csv":
+		return writeValidationResultsCsv(stdout, results)
+	case "html":
+		tmpl, templateErr := template.ParseFS(templatesFS, "static/templates/validation.tmpl.html")
+		if templateErr != nil {
+			return templateErr
+		}
+		return tmpl.Execute(stdout, validationTemplateData(results, strings.Join(flagSet.Args(), ", ")))
+	}
+	return fmt.Errorf("unsupported format '%s'. Supported formats are 'json', 'csv' and 'html'", *format)
+}
@@ -0,0 +1,112 @@
+package idgenerator_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// partnerExport is a CSV file like the ones exported by Excel in a German locale
+const partnerExport = "\xef\xbb\xbfKunde;Marktlokation;Bemerkung\n" +
+	"Müller;41373559241;ok\n" +
+	"Meier;41373559240;falsche Prüfziffer\n" +
+	"Schulze;E1137355921;NeLo\n" +
+	"Schmidt;keine ID;\n"
+
+func (s *Suite) Test_Validate_Single_Values() {
+	then.AssertThat(s.T(), idgenerator.ValidateValue(nil, "41373559241").Valid, is.True())
+	result := idgenerator.ValidateValue(nil, "41373559240")
+	then.AssertThat(s.T(), result.Valid, is.False())
+	then.AssertThat(s.T(), result.Type, is.EqualTo("MaLo"))
+	then.AssertThat(s.T(), result.CorrectedChecksum, is.EqualTo("1"))
+	then.AssertThat(s.T(), result.CorrectedId, is.EqualTo("41373559241"))
+	result = idgenerator.ValidateValue(&idgenerator.NeLoIdType, "41373559241")
+	then.AssertThat(s.T(), result.Valid, is.False())
+	then.AssertThat(s.T(), result.CorrectedId, is.EqualTo(""))
+	result = idgenerator.ValidateValue(nil, "foo")
+	then.AssertThat(s.T(), result.Valid, is.False())
+	then.AssertThat(s.T(), result.Type, is.EqualTo(""))
+	then.AssertThat(s.T(), idgenerator.ValidateValue(nil, "DE0010696664610000000000000012345").Valid, is.True())
+}
+
+func (s *Suite) Test_Validate_Csv_Column_By_Name() {
+	req, _ := http.NewRequest("POST", "/validate?column=Marktlokation", strings.NewReader(partnerExport))
+	req.Header.Set("Content-Type", "text/csv")
+	response := httptest.NewRecorder()
+	idgenerator.NewRouter().ServeHTTP(response, req)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	var results []idgenerator.ValidationResult
+	err := json.NewDecoder(response.Body).Decode(&results)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(results), is.EqualTo(4))
+	then.AssertThat(s.T(), results[0].Row, is.EqualTo(2)) // the header is row 1
+	then.AssertThat(s.T(), results[0].Valid, is.True())
+	then.AssertThat(s.T(), results[1].CorrectedId, is.EqualTo("41373559241"))
+	then.AssertThat(s.T(), results[2].Type, is.EqualTo("NeLo"))
+	then.AssertThat(s.T(), results[2].Valid, is.True())
+	then.AssertThat(s.T(), results[3].Valid, is.False())
+}
+
+func (s *Suite) Test_Validate_Uploaded_Text_File_With_Csv_Report() {
+	var body bytes.Buffer
+	multipartWriter := multipart.NewWriter(&body)
+	fileWriter, _ := multipartWriter.CreateFormFile("file", "ids.txt")
+	_, _ = fileWriter.Write([]byte("E1137355921\n\nE1137355920\n"))
+	_ = multipartWriter.WriteField("type", "NELO")
+	_ = multipartWriter.Close()
+	req, _ := http.NewRequest("POST", "/validate?format=csv", &body)
+	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	response := httptest.NewRecorder()
+	idgenerator.NewRouter().ServeHTTP(response, req)
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	records, err := csv.NewReader(response.Body).ReadAll()
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(records), is.EqualTo(3)) // header + 2 IDs; the empty line is skipped
+	then.AssertThat(s.T(), records[1], is.EqualTo([]string{"1", "E1137355921", "NeLo", "true", "", "", ""}))
+	then.AssertThat(s.T(), records[2][0], is.EqualTo("3"))
+	then.AssertThat(s.T(), records[2][6], is.EqualTo("E1137355921"))
+}
+
+func (s *Suite) Test_Validation_Html_Report() {
+	router := idgenerator.NewRouter()
+	response := performGetRequest(router, "/validate")
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), `<form id="upload-form"`), is.True())
+
+	response = performRequest(router, "POST", "/validate?format=html&column=2", strings.NewReader(partnerExport))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusOK))
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), `<table id="validation-results">`), is.True())
+	then.AssertThat(s.T(), strings.Contains(response.Body.String(), "2 gültig"), is.True())
+}
+
+func (s *Suite) Test_Validate_Unknown_Column() {
+	response := performRequest(idgenerator.NewRouter(), "POST", "/validate?column=Messlokation", strings.NewReader(partnerExport))
+	then.AssertThat(s.T(), response.Code, is.EqualTo(http.StatusBadRequest))
+}
+
+func (s *Suite) Test_Validate_Command() {
+	fileName := filepath.Join(s.T().TempDir(), "export.csv")
+	err := os.WriteFile(fileName, []byte(partnerExport), 0o600)
+	then.AssertThat(s.T(), err, is.Nil())
+	var stdout, stderr bytes.Buffer
+	exitCode := idgenerator.RunCli([]string{"validate", "-column", "Marktlokation", fileName}, strings.NewReader(""), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	var results []idgenerator.ValidationResult
+	err = json.Unmarshal(stdout.Bytes(), &results)
+	then.AssertThat(s.T(), err, is.Nil())
+	then.AssertThat(s.T(), len(results), is.EqualTo(4))
+
+	stdout.Reset()
+	exitCode = idgenerator.RunCli([]string{"validate", "-format", "csv"}, strings.NewReader("41373559240\n"), &stdout, &stderr)
+	then.AssertThat(s.T(), exitCode, is.EqualTo(0))
+	then.AssertThat(s.T(), strings.Contains(stdout.String(), "41373559241"), is.True())
+}
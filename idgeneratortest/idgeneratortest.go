@@ -0,0 +1,112 @@
+// Package idgeneratortest provides helpers for the unit tests of projects that use MaLo, MeLo, NeLo, TR or SR IDs: fresh valid and invalid IDs and an in-process server with the API of the malo-id-generator, so that tests don't depend on the public deployment.
+package idgeneratortest
+
+import (
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Seed makes the IDs of all helpers (and of all servers started with NewServer) reproducible for the rest of the test.
+// The generators are re-seeded with the current time when the test ends.
+// Tests that call Seed must not run in parallel with other tests that generate IDs.
+func Seed(t testing.TB, seed int64) {
+	t.Helper()
+	idgenerator.Seed(seed)
+	t.Cleanup(func() { idgenerator.Seed(time.Now().UnixNano()) })
+}
+
+// NewServer starts an httptest.Server with all routes of the generator and closes it when the test ends.
+// / and /json generate the options' IdType or, if it's empty, the ID_TYPE_TO_GENERATE.
+func NewServer(t testing.TB, options idgenerator.RouteOptions) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(idgenerator.NewHandler("", options))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newId returns a new valid ID of the type or fails the test
+func newId(t testing.TB, idType idgenerator.IdType) string {
+	t.Helper()
+	id, err := idType.NewRandomId()
+	if err != nil {
+		t.Fatalf("could not generate a %s-ID: %v", idType.Label, err)
+	}
+	return id
+}
+
+// MaLo returns a new valid MaLo-ID
+func MaLo(t testing.TB) string {
+	t.Helper()
+	return newId(t, idgenerator.MaLoIdType)
+}
+
+// NeLo returns a new valid NeLo-ID
+func NeLo(t testing.TB) string {
+	t.Helper()
+	return newId(t, idgenerator.NeLoIdType)
+}
+
+// MeLo returns a new valid MeLo-ID
+func MeLo(t testing.TB) string {
+	t.Helper()
+	return newId(t, idgenerator.MeLoIdType)
+}
+
+// TRId returns a new valid TR-ID (Technische Ressource)
+func TRId(t testing.TB) string {
+	t.Helper()
+	return newId(t, idgenerator.TRIdType)
+}
+
+// SRId returns a new valid SR-ID (Steuerbare Ressource)
+func SRId(t testing.TB) string {
+	t.Helper()
+	return newId(t, idgenerator.SRIdType)
+}
+
+// checksumIdTypes are the types whose last character is a check digit
+var checksumIdTypes = []idgenerator.IdType{idgenerator.MaLoIdType, idgenerator.NeLoIdType, idgenerator.TRIdType, idgenerator.SRIdType}
+
+// WithWrongChecksum returns the valid MaLo, NeLo, TR or SR ID with a wrong check digit, i.e. an ID with the correct structure that fails the validation only because of its checksum
+func WithWrongChecksum(t testing.TB, id string) string {
+	t.Helper()
+	for _, idType := range checksumIdTypes {
+		if idType.Validate(id) != nil {
+			continue
+		}
+		checksum, err := strconv.Atoi(id[len(id)-1:])
+		if err != nil {
+			t.Fatalf("the checksum of '%s' is not a digit", id)
+		}
+		return id[:len(id)-1] + strconv.Itoa((checksum+1)%10)
+	}
+	t.Fatalf("'%s' is not a valid ID with checksum", id)
+	return ""
+}
+
+// InvalidMaLo returns a new MaLo-ID with a wrong checksum
+func InvalidMaLo(t testing.TB) string {
+	t.Helper()
+	return WithWrongChecksum(t, MaLo(t))
+}
+
+// InvalidNeLo returns a new NeLo-ID with a wrong checksum
+func InvalidNeLo(t testing.TB) string {
+	t.Helper()
+	return WithWrongChecksum(t, NeLo(t))
+}
+
+// InvalidTRId returns a new TR-ID with a wrong checksum
+func InvalidTRId(t testing.TB) string {
+	t.Helper()
+	return WithWrongChecksum(t, TRId(t))
+}
+
+// InvalidSRId returns a new SR-ID with a wrong checksum
+func InvalidSRId(t testing.TB) string {
+	t.Helper()
+	return WithWrongChecksum(t, SRId(t))
+}
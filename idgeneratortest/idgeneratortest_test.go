@@ -0,0 +1,74 @@
+package idgeneratortest_test
+
+import (
+	"encoding/json"
+	"github.com/corbym/gocrest/is"
+	"github.com/corbym/gocrest/then"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"github.com/hochfrequenz/malo-id-generator/idgeneratortest"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestInit(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) Test_Valid_Ids() {
+	then.AssertThat(s.T(), idgenerator.MaLoIdType.Validate(idgeneratortest.MaLo(s.T())), is.Nil())
+	then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(idgeneratortest.NeLo(s.T())), is.Nil())
+	then.AssertThat(s.T(), idgenerator.MeLoIdType.Validate(idgeneratortest.MeLo(s.T())), is.Nil())
+	then.AssertThat(s.T(), idgenerator.TRIdType.Validate(idgeneratortest.TRId(s.T())), is.Nil())
+	then.AssertThat(s.T(), idgenerator.SRIdType.Validate(idgeneratortest.SRId(s.T())), is.Nil())
+}
+
+func (s *Suite) Test_Ids_With_Wrong_Checksum() {
+	for _, id := range []string{
+		idgeneratortest.InvalidMaLo(s.T()),
+		idgeneratortest.InvalidNeLo(s.T()),
+		idgeneratortest.InvalidTRId(s.T()),
+		idgeneratortest.InvalidSRId(s.T()),
+	} {
+		result := idgenerator.ValidateValue(nil, id)
+		then.AssertThat(s.T(), result.Valid, is.False())
+		then.AssertThat(s.T(), result.CorrectedChecksum != "", is.True()) // i.e. only the checksum is wrong
+	}
+	then.AssertThat(s.T(), idgeneratortest.WithWrongChecksum(s.T(), "41373559241"), is.EqualTo("41373559242"))
+}
+
+func (s *Suite) Test_Seeded_Ids_Are_Reproducible() {
+	idgeneratortest.Seed(s.T(), 42)
+	first := []string{idgeneratortest.MaLo(s.T()), idgeneratortest.MeLo(s.T()), idgeneratortest.InvalidNeLo(s.T())}
+	idgeneratortest.Seed(s.T(), 42)
+	second := []string{idgeneratortest.MaLo(s.T()), idgeneratortest.MeLo(s.T()), idgeneratortest.InvalidNeLo(s.T())}
+	then.AssertThat(s.T(), second, is.EqualTo(first))
+	idgeneratortest.Seed(s.T(), 43)
+	then.AssertThat(s.T(), idgeneratortest.MaLo(s.T()) != first[0], is.True())
+}
+
+// getJsonId requests /json from the server
+func (s *Suite) getJsonId(url string) string {
+	response, err := http.Get(url + "/json")
+	then.AssertThat(s.T(), err, is.Nil())
+	defer func() { _ = response.Body.Close() }()
+	then.AssertThat(s.T(), response.StatusCode, is.EqualTo(http.StatusOK))
+	var body struct {
+		Id string `json:"id"`
+	}
+	then.AssertThat(s.T(), json.NewDecoder(response.Body).Decode(&body), is.Nil())
+	return body.Id
+}
+
+func (s *Suite) Test_Server() {
+	server := idgeneratortest.NewServer(s.T(), idgenerator.RouteOptions{IdType: "NELO"})
+	idgeneratortest.Seed(s.T(), 7)
+	first := s.getJsonId(server.URL)
+	then.AssertThat(s.T(), idgenerator.NeLoIdType.Validate(first), is.Nil())
+	idgeneratortest.Seed(s.T(), 7)
+	then.AssertThat(s.T(), s.getJsonId(server.URL), is.EqualTo(first))
+}
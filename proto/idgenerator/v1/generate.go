@@ -0,0 +1,4 @@
+package idgeneratorv1
+
+// The generated code is checked in, so that neither protoc nor the plugins are required to build the service.
+//go:generate protoc --proto_path=../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative idgenerator/v1/id_generator.proto
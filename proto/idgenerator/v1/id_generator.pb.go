@@ -0,0 +1,794 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.0
+// source: idgenerator/v1/id_generator.proto
+
+// The gRPC API of the malo-id-generator. It offers the same generators, validation and checksum explanations as the HTTP API.
+
+package idgeneratorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// IdType is the type of an ID
+type IdType int32
+
+const (
+	// ID_TYPE_UNSPECIFIED means the configured ID_TYPE_TO_GENERATE (for generation) or the type detected from the ID (for validation and explanation)
+	IdType_ID_TYPE_UNSPECIFIED IdType = 0
+	// ID_TYPE_MALO are Marktlokations-IDs
+	IdType_ID_TYPE_MALO IdType = 1
+	// ID_TYPE_NELO are Netzlokations-IDs
+	IdType_ID_TYPE_NELO IdType = 2
+	// ID_TYPE_MELO are Messlokations-IDs
+	IdType_ID_TYPE_MELO IdType = 3
+	// ID_TYPE_TRID are Technische Ressource-IDs
+	IdType_ID_TYPE_TRID IdType = 4
+	// ID_TYPE_SRID are Steuerbare Ressource-IDs
+	IdType_ID_TYPE_SRID IdType = 5
+)
+
+// Enum value maps for IdType.
+var (
+	IdType_name = map[int32]string{
+		0: "ID_TYPE_UNSPECIFIED",
+		1: "ID_TYPE_MALO",
+		2: "ID_TYPE_NELO",
+		3: "ID_TYPE_MELO",
+		4: "ID_TYPE_TRID",
+		5: "ID_TYPE_SRID",
+	}
+	IdType_value = map[string]int32{
+		"ID_TYPE_UNSPECIFIED": 0,
+		"ID_TYPE_MALO":        1,
+		"ID_TYPE_NELO":        2,
+		"ID_TYPE_MELO":        3,
+		"ID_TYPE_TRID":        4,
+		"ID_TYPE_SRID":        5,
+	}
+)
+
+func (x IdType) Enum() *IdType {
+	p := new(IdType)
+	*p = x
+	return p
+}
+
+func (x IdType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IdType) Descriptor() protoreflect.EnumDescriptor {
+	return file_idgenerator_v1_id_generator_proto_enumTypes[0].Descriptor()
+}
+
+func (IdType) Type() protoreflect.EnumType {
+	return &file_idgenerator_v1_id_generator_proto_enumTypes[0]
+}
+
+func (x IdType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IdType.Descriptor instead.
+func (IdType) EnumDescriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{0}
+}
+
+type GenerateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          IdType                 `protobuf:"varint,1,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateRequest) Reset() {
+	*x = GenerateRequest{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRequest) ProtoMessage() {}
+
+func (x *GenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GenerateRequest) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+type GenerateBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  IdType                 `protobuf:"varint,1,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	// count is the number of IDs (at least 1)
+	Count         int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateBatchRequest) Reset() {
+	*x = GenerateBatchRequest{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateBatchRequest) ProtoMessage() {}
+
+func (x *GenerateBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateBatchRequest.ProtoReflect.Descriptor instead.
+func (*GenerateBatchRequest) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GenerateBatchRequest) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+func (x *GenerateBatchRequest) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GenerateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type  IdType                 `protobuf:"varint,2,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	// checksum is empty for types without a checksum (MeLo)
+	Checksum string `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// details contains all fields of the /json response, e.g. the ID without checksum or the issuer
+	Details       map[string]string `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateResponse) Reset() {
+	*x = GenerateResponse{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateResponse) ProtoMessage() {}
+
+func (x *GenerateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateResponse.ProtoReflect.Descriptor instead.
+func (*GenerateResponse) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GenerateResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+func (x *GenerateResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type ValidateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          IdType                 `protobuf:"varint,2,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ValidateRequest) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+type ValidateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Valid bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// type is the requested or detected type; unspecified if it could not be detected
+	Type IdType `protobuf:"varint,2,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	// reason describes why the ID is invalid
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// corrected_checksum is set if the ID has the correct structure but the wrong checksum
+	CorrectedChecksum string `protobuf:"bytes,4,opt,name=corrected_checksum,json=correctedChecksum,proto3" json:"corrected_checksum,omitempty"`
+	// corrected_id is the ID with the corrected_checksum
+	CorrectedId   string `protobuf:"bytes,5,opt,name=corrected_id,json=correctedId,proto3" json:"corrected_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+func (x *ValidateResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ValidateResponse) GetCorrectedChecksum() string {
+	if x != nil {
+		return x.CorrectedChecksum
+	}
+	return ""
+}
+
+func (x *ValidateResponse) GetCorrectedId() string {
+	if x != nil {
+		return x.CorrectedId
+	}
+	return ""
+}
+
+type ExplainRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is either a complete ID or the ID without checksum
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          IdType `protobuf:"varint,2,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainRequest) Reset() {
+	*x = ExplainRequest{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainRequest) ProtoMessage() {}
+
+func (x *ExplainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainRequest.ProtoReflect.Descriptor instead.
+func (*ExplainRequest) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExplainRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExplainRequest) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+// ChecksumStep describes how a single character of an ID contributes to its checksum
+type ChecksumStep struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// position is 1-based
+	Position  int32  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Character string `protobuf:"bytes,2,opt,name=character,proto3" json:"character,omitempty"`
+	// value is the digit itself or, for letters, its ASCII code
+	Value int32 `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	// weight is 1 for odd and 2 for even positions
+	Weight        int32 `protobuf:"varint,4,opt,name=weight,proto3" json:"weight,omitempty"`
+	Product       int32 `protobuf:"varint,5,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChecksumStep) Reset() {
+	*x = ChecksumStep{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChecksumStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChecksumStep) ProtoMessage() {}
+
+func (x *ChecksumStep) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChecksumStep.ProtoReflect.Descriptor instead.
+func (*ChecksumStep) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ChecksumStep) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *ChecksumStep) GetCharacter() string {
+	if x != nil {
+		return x.Character
+	}
+	return ""
+}
+
+func (x *ChecksumStep) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ChecksumStep) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ChecksumStep) GetProduct() int32 {
+	if x != nil {
+		return x.Product
+	}
+	return 0
+}
+
+type ExplainResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  IdType                 `protobuf:"varint,1,opt,name=type,proto3,enum=idgenerator.v1.IdType" json:"type,omitempty"`
+	// id is the complete ID with the correct checksum
+	Id                string          `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	IdWithoutChecksum string          `protobuf:"bytes,3,opt,name=id_without_checksum,json=idWithoutChecksum,proto3" json:"id_without_checksum,omitempty"`
+	Steps             []*ChecksumStep `protobuf:"bytes,4,rep,name=steps,proto3" json:"steps,omitempty"`
+	OddSum            int32           `protobuf:"varint,5,opt,name=odd_sum,json=oddSum,proto3" json:"odd_sum,omitempty"`
+	EvenSum           int32           `protobuf:"varint,6,opt,name=even_sum,json=evenSum,proto3" json:"even_sum,omitempty"`
+	WeightedEvenSum   int32           `protobuf:"varint,7,opt,name=weighted_even_sum,json=weightedEvenSum,proto3" json:"weighted_even_sum,omitempty"`
+	Sum               int32           `protobuf:"varint,8,opt,name=sum,proto3" json:"sum,omitempty"`
+	Remainder         int32           `protobuf:"varint,9,opt,name=remainder,proto3" json:"remainder,omitempty"`
+	NextMultipleOf_10 int32           `protobuf:"varint,10,opt,name=next_multiple_of_10,json=nextMultipleOf10,proto3" json:"next_multiple_of_10,omitempty"`
+	Checksum          string          `protobuf:"bytes,11,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// given_checksum is the checksum of the explained ID, if a complete ID was given
+	GivenChecksum string `protobuf:"bytes,12,opt,name=given_checksum,json=givenChecksum,proto3" json:"given_checksum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idgenerator_v1_id_generator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_idgenerator_v1_id_generator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExplainResponse) GetType() IdType {
+	if x != nil {
+		return x.Type
+	}
+	return IdType_ID_TYPE_UNSPECIFIED
+}
+
+func (x *ExplainResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExplainResponse) GetIdWithoutChecksum() string {
+	if x != nil {
+		return x.IdWithoutChecksum
+	}
+	return ""
+}
+
+func (x *ExplainResponse) GetSteps() []*ChecksumStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetOddSum() int32 {
+	if x != nil {
+		return x.OddSum
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetEvenSum() int32 {
+	if x != nil {
+		return x.EvenSum
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetWeightedEvenSum() int32 {
+	if x != nil {
+		return x.WeightedEvenSum
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetSum() int32 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetRemainder() int32 {
+	if x != nil {
+		return x.Remainder
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetNextMultipleOf_10() int32 {
+	if x != nil {
+		return x.NextMultipleOf_10
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *ExplainResponse) GetGivenChecksum() string {
+	if x != nil {
+		return x.GivenChecksum
+	}
+	return ""
+}
+
+var File_idgenerator_v1_id_generator_proto protoreflect.FileDescriptor
+
+const file_idgenerator_v1_id_generator_proto_rawDesc = "" +
+	"\n" +
+	"!idgenerator/v1/id_generator.proto\x12\x0eidgenerator.v1\"=\n" +
+	"\x0fGenerateRequest\x12*\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\"X\n" +
+	"\x14GenerateBatchRequest\x12*\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\xef\x01\n" +
+	"\x10GenerateResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\x12\x1a\n" +
+	"\bchecksum\x18\x03 \x01(\tR\bchecksum\x12G\n" +
+	"\adetails\x18\x04 \x03(\v2-.idgenerator.v1.GenerateResponse.DetailsEntryR\adetails\x1a:\n" +
+	"\fDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"M\n" +
+	"\x0fValidateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\"\xbe\x01\n" +
+	"\x10ValidateResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12-\n" +
+	"\x12corrected_checksum\x18\x04 \x01(\tR\x11correctedChecksum\x12!\n" +
+	"\fcorrected_id\x18\x05 \x01(\tR\vcorrectedId\"L\n" +
+	"\x0eExplainRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\"\x90\x01\n" +
+	"\fChecksumStep\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x05R\bposition\x12\x1c\n" +
+	"\tcharacter\x18\x02 \x01(\tR\tcharacter\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x05R\x05value\x12\x16\n" +
+	"\x06weight\x18\x04 \x01(\x05R\x06weight\x12\x18\n" +
+	"\aproduct\x18\x05 \x01(\x05R\aproduct\"\xb3\x03\n" +
+	"\x0fExplainResponse\x12*\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x16.idgenerator.v1.IdTypeR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12.\n" +
+	"\x13id_without_checksum\x18\x03 \x01(\tR\x11idWithoutChecksum\x122\n" +
+	"\x05steps\x18\x04 \x03(\v2\x1c.idgenerator.v1.ChecksumStepR\x05steps\x12\x17\n" +
+	"\aodd_sum\x18\x05 \x01(\x05R\x06oddSum\x12\x19\n" +
+	"\beven_sum\x18\x06 \x01(\x05R\aevenSum\x12*\n" +
+	"\x11weighted_even_sum\x18\a \x01(\x05R\x0fweightedEvenSum\x12\x10\n" +
+	"\x03sum\x18\b \x01(\x05R\x03sum\x12\x1c\n" +
+	"\tremainder\x18\t \x01(\x05R\tremainder\x12-\n" +
+	"\x13next_multiple_of_10\x18\n" +
+	" \x01(\x05R\x10nextMultipleOf10\x12\x1a\n" +
+	"\bchecksum\x18\v \x01(\tR\bchecksum\x12%\n" +
+	"\x0egiven_checksum\x18\f \x01(\tR\rgivenChecksum*{\n" +
+	"\x06IdType\x12\x17\n" +
+	"\x13ID_TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fID_TYPE_MALO\x10\x01\x12\x10\n" +
+	"\fID_TYPE_NELO\x10\x02\x12\x10\n" +
+	"\fID_TYPE_MELO\x10\x03\x12\x10\n" +
+	"\fID_TYPE_TRID\x10\x04\x12\x10\n" +
+	"\fID_TYPE_SRID\x10\x052\xd9\x02\n" +
+	"\x12IdGeneratorService\x12M\n" +
+	"\bGenerate\x12\x1f.idgenerator.v1.GenerateRequest\x1a .idgenerator.v1.GenerateResponse\x12Y\n" +
+	"\rGenerateBatch\x12$.idgenerator.v1.GenerateBatchRequest\x1a .idgenerator.v1.GenerateResponse0\x01\x12M\n" +
+	"\bValidate\x12\x1f.idgenerator.v1.ValidateRequest\x1a .idgenerator.v1.ValidateResponse\x12J\n" +
+	"\aExplain\x12\x1e.idgenerator.v1.ExplainRequest\x1a\x1f.idgenerator.v1.ExplainResponseBNZLgithub.com/hochfrequenz/malo-id-generator/proto/idgenerator/v1;idgeneratorv1b\x06proto3"
+
+var (
+	file_idgenerator_v1_id_generator_proto_rawDescOnce sync.Once
+	file_idgenerator_v1_id_generator_proto_rawDescData []byte
+)
+
+func file_idgenerator_v1_id_generator_proto_rawDescGZIP() []byte {
+	file_idgenerator_v1_id_generator_proto_rawDescOnce.Do(func() {
+		file_idgenerator_v1_id_generator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idgenerator_v1_id_generator_proto_rawDesc), len(file_idgenerator_v1_id_generator_proto_rawDesc)))
+	})
+	return file_idgenerator_v1_id_generator_proto_rawDescData
+}
+
+var file_idgenerator_v1_id_generator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_idgenerator_v1_id_generator_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_idgenerator_v1_id_generator_proto_goTypes = []any{
+	(IdType)(0),                  // 0: idgenerator.v1.IdType
+	(*GenerateRequest)(nil),      // 1: idgenerator.v1.GenerateRequest
+	(*GenerateBatchRequest)(nil), // 2: idgenerator.v1.GenerateBatchRequest
+	(*GenerateResponse)(nil),     // 3: idgenerator.v1.GenerateResponse
+	(*ValidateRequest)(nil),      // 4: idgenerator.v1.ValidateRequest
+	(*ValidateResponse)(nil),     // 5: idgenerator.v1.ValidateResponse
+	(*ExplainRequest)(nil),       // 6: idgenerator.v1.ExplainRequest
+	(*ChecksumStep)(nil),         // 7: idgenerator.v1.ChecksumStep
+	(*ExplainResponse)(nil),      // 8: idgenerator.v1.ExplainResponse
+	nil,                          // 9: idgenerator.v1.GenerateResponse.DetailsEntry
+}
+var file_idgenerator_v1_id_generator_proto_depIdxs = []int32{
+	0,  // 0: idgenerator.v1.GenerateRequest.type:type_name -> idgenerator.v1.IdType
+	0,  // 1: idgenerator.v1.GenerateBatchRequest.type:type_name -> idgenerator.v1.IdType
+	0,  // 2: idgenerator.v1.GenerateResponse.type:type_name -> idgenerator.v1.IdType
+	9,  // 3: idgenerator.v1.GenerateResponse.details:type_name -> idgenerator.v1.GenerateResponse.DetailsEntry
+	0,  // 4: idgenerator.v1.ValidateRequest.type:type_name -> idgenerator.v1.IdType
+	0,  // 5: idgenerator.v1.ValidateResponse.type:type_name -> idgenerator.v1.IdType
+	0,  // 6: idgenerator.v1.ExplainRequest.type:type_name -> idgenerator.v1.IdType
+	0,  // 7: idgenerator.v1.ExplainResponse.type:type_name -> idgenerator.v1.IdType
+	7,  // 8: idgenerator.v1.ExplainResponse.steps:type_name -> idgenerator.v1.ChecksumStep
+	1,  // 9: idgenerator.v1.IdGeneratorService.Generate:input_type -> idgenerator.v1.GenerateRequest
+	2,  // 10: idgenerator.v1.IdGeneratorService.GenerateBatch:input_type -> idgenerator.v1.GenerateBatchRequest
+	4,  // 11: idgenerator.v1.IdGeneratorService.Validate:input_type -> idgenerator.v1.ValidateRequest
+	6,  // 12: idgenerator.v1.IdGeneratorService.Explain:input_type -> idgenerator.v1.ExplainRequest
+	3,  // 13: idgenerator.v1.IdGeneratorService.Generate:output_type -> idgenerator.v1.GenerateResponse
+	3,  // 14: idgenerator.v1.IdGeneratorService.GenerateBatch:output_type -> idgenerator.v1.GenerateResponse
+	5,  // 15: idgenerator.v1.IdGeneratorService.Validate:output_type -> idgenerator.v1.ValidateResponse
+	8,  // 16: idgenerator.v1.IdGeneratorService.Explain:output_type -> idgenerator.v1.ExplainResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_idgenerator_v1_id_generator_proto_init() }
+func file_idgenerator_v1_id_generator_proto_init() {
+	if File_idgenerator_v1_id_generator_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idgenerator_v1_id_generator_proto_rawDesc), len(file_idgenerator_v1_id_generator_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_idgenerator_v1_id_generator_proto_goTypes,
+		DependencyIndexes: file_idgenerator_v1_id_generator_proto_depIdxs,
+		EnumInfos:         file_idgenerator_v1_id_generator_proto_enumTypes,
+		MessageInfos:      file_idgenerator_v1_id_generator_proto_msgTypes,
+	}.Build()
+	File_idgenerator_v1_id_generator_proto = out.File
+	file_idgenerator_v1_id_generator_proto_goTypes = nil
+	file_idgenerator_v1_id_generator_proto_depIdxs = nil
+}
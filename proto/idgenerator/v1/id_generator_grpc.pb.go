@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v6.33.0
+// source: idgenerator/v1/id_generator.proto
+
+// The gRPC API of the malo-id-generator. It offers the same generators, validation and checksum explanations as the HTTP API.
+
+package idgeneratorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IdGeneratorService_Generate_FullMethodName      = "/idgenerator.v1.IdGeneratorService/Generate"
+	IdGeneratorService_GenerateBatch_FullMethodName = "/idgenerator.v1.IdGeneratorService/GenerateBatch"
+	IdGeneratorService_Validate_FullMethodName      = "/idgenerator.v1.IdGeneratorService/Validate"
+	IdGeneratorService_Explain_FullMethodName       = "/idgenerator.v1.IdGeneratorService/Explain"
+)
+
+// IdGeneratorServiceClient is the client API for IdGeneratorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IdGeneratorService generates, validates and explains IDs of the German energy market
+type IdGeneratorServiceClient interface {
+	// Generate returns a single freshly generated ID (the same as GET /json)
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error)
+	// GenerateBatch streams count freshly generated IDs
+	GenerateBatch(ctx context.Context, in *GenerateBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateResponse], error)
+	// Validate checks whether a value is a valid ID and, if only the checksum is wrong, suggests the corrected ID
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	// Explain calculates the checksum of an ID step by step
+	Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error)
+}
+
+type idGeneratorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIdGeneratorServiceClient(cc grpc.ClientConnInterface) IdGeneratorServiceClient {
+	return &idGeneratorServiceClient{cc}
+}
+
+func (c *idGeneratorServiceClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateResponse)
+	err := c.cc.Invoke(ctx, IdGeneratorService_Generate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *idGeneratorServiceClient) GenerateBatch(ctx context.Context, in *GenerateBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IdGeneratorService_ServiceDesc.Streams[0], IdGeneratorService_GenerateBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GenerateBatchRequest, GenerateResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IdGeneratorService_GenerateBatchClient = grpc.ServerStreamingClient[GenerateResponse]
+
+func (c *idGeneratorServiceClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, IdGeneratorService_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *idGeneratorServiceClient) Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainResponse)
+	err := c.cc.Invoke(ctx, IdGeneratorService_Explain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IdGeneratorServiceServer is the server API for IdGeneratorService service.
+// All implementations must embed UnimplementedIdGeneratorServiceServer
+// for forward compatibility.
+//
+// IdGeneratorService generates, validates and explains IDs of the German energy market
+type IdGeneratorServiceServer interface {
+	// Generate returns a single freshly generated ID (the same as GET /json)
+	Generate(context.Context, *GenerateRequest) (*GenerateResponse, error)
+	// GenerateBatch streams count freshly generated IDs
+	GenerateBatch(*GenerateBatchRequest, grpc.ServerStreamingServer[GenerateResponse]) error
+	// Validate checks whether a value is a valid ID and, if only the checksum is wrong, suggests the corrected ID
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	// Explain calculates the checksum of an ID step by step
+	Explain(context.Context, *ExplainRequest) (*ExplainResponse, error)
+	mustEmbedUnimplementedIdGeneratorServiceServer()
+}
+
+// UnimplementedIdGeneratorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIdGeneratorServiceServer struct{}
+
+func (UnimplementedIdGeneratorServiceServer) Generate(context.Context, *GenerateRequest) (*GenerateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedIdGeneratorServiceServer) GenerateBatch(*GenerateBatchRequest, grpc.ServerStreamingServer[GenerateResponse]) error {
+	return status.Error(codes.Unimplemented, "method GenerateBatch not implemented")
+}
+func (UnimplementedIdGeneratorServiceServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedIdGeneratorServiceServer) Explain(context.Context, *ExplainRequest) (*ExplainResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Explain not implemented")
+}
+func (UnimplementedIdGeneratorServiceServer) mustEmbedUnimplementedIdGeneratorServiceServer() {}
+func (UnimplementedIdGeneratorServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeIdGeneratorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IdGeneratorServiceServer will
+// result in compilation errors.
+type UnsafeIdGeneratorServiceServer interface {
+	mustEmbedUnimplementedIdGeneratorServiceServer()
+}
+
+func RegisterIdGeneratorServiceServer(s grpc.ServiceRegistrar, srv IdGeneratorServiceServer) {
+	// If the following call panics, it indicates UnimplementedIdGeneratorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IdGeneratorService_ServiceDesc, srv)
+}
+
+func _IdGeneratorService_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdGeneratorServiceServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IdGeneratorService_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdGeneratorServiceServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IdGeneratorService_GenerateBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IdGeneratorServiceServer).GenerateBatch(m, &grpc.GenericServerStream[GenerateBatchRequest, GenerateResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IdGeneratorService_GenerateBatchServer = grpc.ServerStreamingServer[GenerateResponse]
+
+func _IdGeneratorService_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdGeneratorServiceServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IdGeneratorService_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdGeneratorServiceServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IdGeneratorService_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IdGeneratorServiceServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IdGeneratorService_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IdGeneratorServiceServer).Explain(ctx, req.(*ExplainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IdGeneratorService_ServiceDesc is the grpc.ServiceDesc for IdGeneratorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IdGeneratorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "idgenerator.v1.IdGeneratorService",
+	HandlerType: (*IdGeneratorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Generate",
+			Handler:    _IdGeneratorService_Generate_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _IdGeneratorService_Validate_Handler,
+		},
+		{
+			MethodName: "Explain",
+			Handler:    _IdGeneratorService_Explain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateBatch",
+			Handler:       _IdGeneratorService_GenerateBatch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "idgenerator/v1/id_generator.proto",
+}
@@ -0,0 +1,36 @@
+// Package testmodule is a separate go module that imports the helper packages like any other project would.
+// It's built from a git archive in CI, i.e. without the companystylesheet submodule, just like the go module proxy serves the packages.
+package testmodule
+
+import (
+	"encoding/json"
+	"github.com/hochfrequenz/malo-id-generator/idgenerator"
+	"github.com/hochfrequenz/malo-id-generator/idgeneratortest"
+	"net/http"
+	"testing"
+)
+
+func TestHelpersCanBeImported(t *testing.T) {
+	idgeneratortest.Seed(t, 42)
+	if err := idgenerator.MaLoIdType.Validate(idgeneratortest.MaLo(t)); err != nil {
+		t.Fatal(err)
+	}
+	if idgenerator.NeLoIdType.Validate(idgeneratortest.InvalidNeLo(t)) == nil {
+		t.Fatal("the invalid NeLo-ID is valid")
+	}
+	server := idgeneratortest.NewServer(t, idgenerator.RouteOptions{IdType: "SRID"})
+	response, err := http.Get(server.URL + "/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	var body struct {
+		Id string `json:"id"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if err = idgenerator.SRIdType.Validate(body.Id); err != nil {
+		t.Fatal(err)
+	}
+}